@@ -44,7 +44,17 @@ CREATE TABLE users (
 
     last_ip              TEXT         NOT NULL,
     datetime_created     TIMESTAMPTZ  NOT NULL  DEFAULT NOW(),
-    datetime_last_login  TIMESTAMPTZ  NOT NULL  DEFAULT NOW()
+    datetime_last_login  TIMESTAMPTZ  NOT NULL  DEFAULT NOW(),
+
+    /**
+     * Duplicate accounts are merged into another account (with the "Users.Merge()" function).
+     * The row is kept so that the old username remains reserved. Null for normal users.
+     *
+     * TODO: Add this column on the server:
+     * ALTER TABLE users ADD COLUMN merged_into INTEGER NULL REFERENCES users (id) ON DELETE SET NULL;
+     */
+    merged_into          INTEGER      NULL      DEFAULT NULL,
+    FOREIGN KEY (merged_into) REFERENCES users (id) ON DELETE SET NULL
 );
 
 /* Any default settings must also be applied to the "userSettings.go" file. */
@@ -80,6 +90,16 @@ CREATE TABLE user_settings (
     FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
 );
 
+/* The optional fields that a user can fill in on their profile. */
+DROP TABLE IF EXISTS user_profiles CASCADE;
+CREATE TABLE user_profiles (
+    user_id               INTEGER   PRIMARY KEY,
+    pronouns              TEXT      NOT NULL  DEFAULT '',
+    bio                   TEXT      NOT NULL  DEFAULT '', /* The maximum length is enforced in Golang. */
+    favorite_variant_id   SMALLINT  NULL      DEFAULT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
 /* User stats are per variant. */
 DROP TABLE IF EXISTS user_stats CASCADE;
 CREATE TABLE user_stats (
@@ -104,6 +124,21 @@ CREATE TABLE user_stats (
     PRIMARY KEY (user_id, variant_id)
 );
 
+/**
+ * User ratings are per variant and per number of players. Players without a row for a particular
+ * variant and number of players have the default rating. (See "DefaultRating" in "constants.go".)
+ */
+DROP TABLE IF EXISTS user_ratings CASCADE;
+CREATE TABLE user_ratings (
+    user_id      INTEGER   NOT NULL,
+    variant_id   SMALLINT  NOT NULL,
+    num_players  SMALLINT  NOT NULL,
+    rating       FLOAT     NOT NULL,
+    num_games    INTEGER   NOT NULL  DEFAULT 0,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, variant_id, num_players)
+);
+
 DROP TABLE IF EXISTS user_friends CASCADE;
 CREATE TABLE user_friends (
     user_id    INTEGER  NOT NULL,
@@ -150,6 +185,13 @@ CREATE TABLE games (
     time_base               INTEGER      NOT NULL, /* in seconds */
     time_per_turn           INTEGER      NOT NULL, /* in seconds */
     speedrun                BOOLEAN      NOT NULL,
+    /**
+     * Only speedruns can have a turn time limit.
+     *
+     * TODO: Add this column on the server:
+     * ALTER TABLE games ADD COLUMN turn_time_limit BOOLEAN NOT NULL DEFAULT FALSE;
+     */
+    turn_time_limit         BOOLEAN      NOT NULL  DEFAULT FALSE,
     card_cycle              BOOLEAN      NOT NULL,
     deck_plays              BOOLEAN      NOT NULL,
     empty_clues             BOOLEAN      NOT NULL,
@@ -157,16 +199,45 @@ CREATE TABLE games (
     one_less_card           BOOLEAN      NOT NULL,
     all_or_nothing          BOOLEAN      NOT NULL,
     detrimental_characters  BOOLEAN      NOT NULL,
+    /**
+     * The IDs of the characters that could be dealt in the game. This is null if every character
+     * was allowed (or if the game did not have detrimental characters).
+     *
+     * TODO: Add this column on the server:
+     * ALTER TABLE games ADD COLUMN allowed_characters INTEGER[] NULL;
+     */
+    allowed_characters      INTEGER[]    NULL,
 
     seed                    TEXT         NOT NULL, /* e.g. "p2v0s1" */
     score                   SMALLINT     NOT NULL,
     num_turns               SMALLINT     NOT NULL,
 
+    /**
+     * The number of strikes at the end of the game. This is null for games that were played before
+     * the column was added.
+     *
+     * TODO: Add this column on the server:
+     * ALTER TABLE games ADD COLUMN num_strikes SMALLINT NULL;
+     */
+    num_strikes             SMALLINT     NULL,
+
     /* See the "EndCondition" values in "constants.go" / "EndCondition.ts". */
     end_condition           SMALLINT     NOT NULL,
 
     datetime_started        TIMESTAMPTZ  NOT NULL,
-    datetime_finished       TIMESTAMPTZ  NOT NULL
+    datetime_finished       TIMESTAMPTZ  NOT NULL,
+
+    /**
+     * Games that are deleted by a moderator are hidden from the history, the leaderboards, and the
+     * stats, but the row is kept for auditing purposes. Both columns are null for normal games.
+     *
+     * TODO: Add these columns on the server:
+     * ALTER TABLE games ADD COLUMN datetime_deleted TIMESTAMPTZ NULL;
+     * ALTER TABLE games ADD COLUMN deleted_by INTEGER NULL REFERENCES users (id) ON DELETE SET NULL;
+     */
+    datetime_deleted        TIMESTAMPTZ  NULL      DEFAULT NULL,
+    deleted_by              INTEGER      NULL      DEFAULT NULL,
+    FOREIGN KEY (deleted_by) REFERENCES users (id) ON DELETE SET NULL
 );
 CREATE INDEX games_index_num_players ON games (num_players);
 CREATE INDEX games_index_variant_id  ON games (variant_id);
@@ -179,15 +250,38 @@ CREATE TABLE game_participants (
     user_id               INTEGER   NOT NULL,
     seat                  SMALLINT  NOT NULL, /* Needed for the "GetNotes()" function */
     character_assignment  SMALLINT  NOT NULL,
-    character_metadata    SMALLINT  NOT NULL,
+    /**
+     * A JSON object so that characters can keep track of more than one value. Games played before
+     * this column was converted from an integer store their metadata as "{"legacy": N}". (See the
+     * "migrate_character_metadata_to_json.py" script.)
+     */
+    character_metadata    JSONB     NOT NULL  DEFAULT '{}',
+    /**
+     * Null if the player was in their seat when the game ended. Only the latest disconnection is
+     * stored.
+     *
+     * TODO: Add this column on the server:
+     * ALTER TABLE game_participants ADD COLUMN datetime_disconnected TIMESTAMPTZ NULL;
+     */
+    datetime_disconnected TIMESTAMPTZ NULL      DEFAULT NULL,
     FOREIGN KEY (game_id) REFERENCES games (id) ON DELETE CASCADE,
     FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
     CONSTRAINT game_participants_unique UNIQUE (game_id, user_id)
 );
 
+/**
+ * Merging users removes the duplicate participant row of a game that both users played in, so it
+ * sets "hanabi.merging_users" for the duration of its transaction to keep the game. (See the
+ * "Users.Merge()" function.)
+ *
+ * TODO: Recreate this function on the server with the definition below.
+ */
 DROP FUNCTION IF EXISTS delete_game_of_deleted_participant;
 CREATE FUNCTION delete_game_of_deleted_participant() RETURNS TRIGGER AS $_$
 BEGIN
+IF current_setting('hanabi.merging_users', true) = 'on' THEN
+    RETURN OLD;
+END IF;
 DELETE FROM games WHERE games.id = OLD.game_id;
 RETURN OLD;
 END $_$ LANGUAGE 'plpgsql';
@@ -240,18 +334,148 @@ CREATE TABLE game_actions (
      */
     value    SMALLINT  NOT NULL,
 
+    /**
+     * The amount of time that the player spent on this action, in milliseconds. Time spent while
+     * the game was paused is not included. Null for "game over" actions and for games that were
+     * played before this column existed.
+     *
+     * TODO: Add this column on the server:
+     * ALTER TABLE game_actions ADD COLUMN think_time INTEGER NULL;
+     */
+    think_time  INTEGER  NULL  DEFAULT NULL,
+
     FOREIGN KEY (game_id) REFERENCES games (id) ON DELETE CASCADE,
     PRIMARY KEY (game_id, turn)
 );
 
+/**
+ * The deck is normally not stored, since it can be derived from the seed. Only games that were
+ * imported from a JSON deck definition (e.g. puzzles) have rows in this table.
+ */
+DROP TABLE IF EXISTS game_decks CASCADE;
+CREATE TABLE game_decks (
+    game_id     INTEGER   NOT NULL,
+    card_order  SMALLINT  NOT NULL, /* "order" is a reserved word in PostgreSQL. */
+    suit_index  SMALLINT  NOT NULL,
+    rank        SMALLINT  NOT NULL,
+    FOREIGN KEY (game_id) REFERENCES games (id) ON DELETE CASCADE,
+    PRIMARY KEY (game_id, card_order)
+);
+
+/**
+ * Hypotheticals that were saved from a shared replay. Each one is a list of actions that are
+ * performed on top of the real game, starting from "start_turn". A game can have more than one.
+ */
+DROP TABLE IF EXISTS hypotheticals CASCADE;
+CREATE TABLE hypotheticals (
+    id                SERIAL       PRIMARY KEY,
+    game_id           INTEGER      NOT NULL,
+    name              TEXT         NOT NULL,
+    start_turn        SMALLINT     NOT NULL,
+    datetime_created  TIMESTAMPTZ  NOT NULL  DEFAULT NOW(),
+    FOREIGN KEY (game_id) REFERENCES games (id) ON DELETE CASCADE
+);
+CREATE INDEX hypotheticals_index_game_id ON hypotheticals (game_id);
+
+/* The columns are the same as the "game_actions" table. */
+DROP TABLE IF EXISTS hypothetical_actions CASCADE;
+CREATE TABLE hypothetical_actions (
+    hypothetical_id  INTEGER   NOT NULL,
+    action_index     SMALLINT  NOT NULL,
+    type             SMALLINT  NOT NULL,
+    target           SMALLINT  NOT NULL,
+    value            SMALLINT  NOT NULL,
+    FOREIGN KEY (hypothetical_id) REFERENCES hypotheticals (id) ON DELETE CASCADE,
+    PRIMARY KEY (hypothetical_id, action_index)
+);
+
+/**
+ * Snapshots of ongoing games so that they can be restored if the server crashes. Ongoing games do
+ * not have a row in the "games" table yet, so they are keyed by the table ID. "state" is the table
+ * in the same JSON format as the files that are written during a graceful restart.
+ */
+DROP TABLE IF EXISTS game_checkpoints CASCADE;
+CREATE TABLE game_checkpoints (
+    table_id        BIGINT       PRIMARY KEY,
+    state           JSONB        NOT NULL,
+    datetime_saved  TIMESTAMPTZ  NOT NULL
+);
+
+/**
+ * Bookmarks of specific turns in a replay, each with a comment. "turn" is the same 0-indexed turn
+ * (i.e. replay segment) that is used in shared replays.
+ */
+DROP TABLE IF EXISTS replay_bookmarks CASCADE;
+CREATE TABLE replay_bookmarks (
+    id                SERIAL       PRIMARY KEY,
+    game_id           INTEGER      NOT NULL,
+    user_id           INTEGER      NOT NULL,
+    turn              SMALLINT     NOT NULL,
+    comment           TEXT         NOT NULL,
+    datetime_created  TIMESTAMPTZ  NOT NULL  DEFAULT NOW(),
+    FOREIGN KEY (game_id) REFERENCES games (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX replay_bookmarks_index_game_id ON replay_bookmarks (game_id);
+
+/* A series of linked games that are played by a named team, where the scores carry forward. */
+DROP TABLE IF EXISTS campaigns CASCADE;
+CREATE TABLE campaigns (
+    id                SERIAL       PRIMARY KEY,
+    name              TEXT         NOT NULL,
+    datetime_created  TIMESTAMPTZ  NOT NULL  DEFAULT NOW()
+);
+
+DROP TABLE IF EXISTS campaign_members CASCADE;
+CREATE TABLE campaign_members (
+    campaign_id  INTEGER  NOT NULL,
+    user_id      INTEGER  NOT NULL,
+    FOREIGN KEY (campaign_id) REFERENCES campaigns (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (campaign_id, user_id)
+);
+CREATE INDEX campaign_members_index_user_id ON campaign_members (user_id);
+
+/* A game can only belong to one campaign, so "game_id" is the primary key. */
+DROP TABLE IF EXISTS campaign_games CASCADE;
+CREATE TABLE campaign_games (
+    game_id      INTEGER  PRIMARY KEY,
+    campaign_id  INTEGER  NOT NULL,
+    FOREIGN KEY (game_id) REFERENCES games (id) ON DELETE CASCADE,
+    FOREIGN KEY (campaign_id) REFERENCES campaigns (id) ON DELETE CASCADE
+);
+CREATE INDEX campaign_games_index_campaign_id ON campaign_games (campaign_id);
+
 DROP TABLE IF EXISTS game_tags CASCADE;
 CREATE TABLE game_tags (
     game_id  INTEGER  NOT NULL,
     user_id  INTEGER  NOT NULL,
     tag      TEXT     NOT NULL,
     FOREIGN KEY (game_id) REFERENCES games (id) ON DELETE CASCADE,
-    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    /**
+     * TODO: Add this constraint on the server (after removing any duplicate rows):
+     * ALTER TABLE game_tags ADD CONSTRAINT game_tags_unique UNIQUE (game_id, user_id, tag);
+     */
+    CONSTRAINT game_tags_unique UNIQUE (game_id, user_id, tag)
 );
+CREATE INDEX game_tags_index_user_id ON game_tags (user_id);
+
+/**
+ * Achievement-style events that happened in a game (e.g. a perfect score). The events are detected
+ * from the action log with the "DetectGameEvents()" function.
+ */
+DROP TABLE IF EXISTS game_events CASCADE;
+CREATE TABLE game_events (
+    game_id           INTEGER      NOT NULL,
+    user_id           INTEGER      NOT NULL,
+    event_type        TEXT         NOT NULL, /* The values are listed in "game_events.go". */
+    datetime_created  TIMESTAMPTZ  NOT NULL  DEFAULT NOW(),
+    FOREIGN KEY (game_id) REFERENCES games (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (game_id, user_id, event_type)
+);
+CREATE INDEX game_events_index_user_id_event_type ON game_events (user_id, event_type);
 
 DROP TABLE IF EXISTS seeds CASCADE;
 CREATE TABLE seeds (
@@ -281,7 +505,12 @@ CREATE TABLE chat_log (
     user_id        INTEGER      NOT NULL, /* 0 is a Discord message. */
     discord_name   TEXT         NULL,     /* Only used if it is a Discord message. */
     message        TEXT         NOT NULL,
-    room           TEXT         NOT NULL, /* Either "lobby" or "table####". */
+    /**
+     * Either "lobby", "table####", or "game####". The chat of a game is written to "game####" when
+     * the game ends, so "table####" rows are no longer written for games. (Older rows may still use
+     * "table####"; see the "ChatLog.ReassignTable()" function.)
+     */
+    room           TEXT         NOT NULL,
     datetime_sent  TIMESTAMPTZ  NOT NULL  DEFAULT NOW()
     /**
      * There is no foreign key for "user_id" because it would not exist for Discord messages or
@@ -353,6 +582,42 @@ CREATE TABLE muted_ips (
 );
 CREATE INDEX muted_ips_index_ip ON muted_ips (ip);
 
+/**
+ * An audit log of moderator actions (e.g. mutes, bans, and game deletions). Rows are only ever
+ * inserted; there are intentionally no functions to update or delete them. (The only exception is
+ * when a user is merged into another user, in which case the user IDs are reassigned.)
+ */
+DROP TABLE IF EXISTS mod_log CASCADE;
+CREATE TABLE mod_log (
+    id                SERIAL       PRIMARY KEY,
+    moderator_id      INTEGER      NOT NULL, /* 0 is the server (e.g. an automatic ban). */
+    target_user_id    INTEGER      NOT NULL,
+    action            TEXT         NOT NULL, /* The values are listed in "models_mod_log.go". */
+    reason            TEXT         NOT NULL,
+    datetime_created  TIMESTAMPTZ  NOT NULL  DEFAULT NOW()
+    /**
+     * There are no foreign keys because the log must be kept even if the users are deleted (and
+     * because the server is not a user).
+     */
+);
+CREATE INDEX mod_log_index_target_user_id ON mod_log (target_user_id);
+CREATE INDEX mod_log_index_moderator_id   ON mod_log (moderator_id);
+
+/**
+ * Recent actions of each user that are rate-limited (e.g. creating a table). Rows are periodically
+ * pruned once they are too old to count towards any rate limit.
+ */
+DROP TABLE IF EXISTS rate_limits CASCADE;
+CREATE TABLE rate_limits (
+    id                SERIAL       PRIMARY KEY,
+    user_id           INTEGER      NOT NULL,
+    action            TEXT         NOT NULL, /* The values are listed in "models_rate_limits.go". */
+    datetime_created  TIMESTAMPTZ  NOT NULL  DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX rate_limits_index_user_id_action ON rate_limits (user_id, action, datetime_created);
+CREATE INDEX rate_limits_index_datetime_created ON rate_limits (datetime_created);
+
 /** TODO: Delete this table once the server is rewritten in TypeScript. */
 DROP TABLE IF EXISTS metadata CASCADE;
 CREATE TABLE metadata (
@@ -23,7 +23,9 @@ export function shouldStoreSegment(
     action.endCondition !== EndCondition.Timeout &&
     action.endCondition !== EndCondition.TerminatedByPlayer &&
     action.endCondition !== EndCondition.TerminatedByVote &&
-    action.endCondition !== EndCondition.IdleTimeout
+    action.endCondition !== EndCondition.IdleTimeout &&
+    action.endCondition !== EndCondition.Abandoned &&
+    action.endCondition !== EndCondition.Resigned
   ) {
     return true;
   }
@@ -11,4 +11,6 @@ export enum EndCondition {
   AllOrNothingFail = 8,
   AllOrNothingSoftlock = 9,
   TerminatedByVote = 10,
+  Abandoned = 11,
+  Resigned = 12,
 }
@@ -19,6 +19,7 @@ export const options = z
     timeBase: z.number().default(0),
     timePerTurn: z.number().int().default(0),
     speedrun: z.boolean().default(false),
+    turnTimeLimit: z.boolean().default(false),
     cardCycle: z.boolean().default(false),
     deckPlays: z.boolean().default(false),
     emptyClues: z.boolean().default(false),
@@ -27,6 +28,9 @@ export const options = z
     allOrNothing: z.boolean().default(false),
     detrimentalCharacters: z.boolean().default(false),
 
+    /** The IDs of the characters that can be dealt. If omitted, every character is allowed. */
+    allowedCharacters: z.array(z.number().int()).optional(),
+
     tableName: z.string().min(1).optional(),
     maxPlayers: numPlayers.optional(),
   })
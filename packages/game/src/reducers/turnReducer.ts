@@ -161,7 +161,9 @@ function turnReducerFunction(
         action.endCondition === EndCondition.Timeout ||
         action.endCondition === EndCondition.TerminatedByPlayer ||
         action.endCondition === EndCondition.TerminatedByVote ||
-        action.endCondition === EndCondition.IdleTimeout
+        action.endCondition === EndCondition.IdleTimeout ||
+        action.endCondition === EndCondition.Abandoned ||
+        action.endCondition === EndCondition.Resigned
       ) {
         turn.segment++;
       }
@@ -156,6 +156,15 @@ export function getGameOverText(
       return "Players were idle for too long.";
     }
 
+    case EndCondition.Abandoned: {
+      return "Players were disconnected for too long.";
+    }
+
+    case EndCondition.Resigned: {
+      const playerNames = getPlayerNames(votes, metadata);
+      return `${playerNames} voted to resign the game.`;
+    }
+
     case EndCondition.CharacterSoftlock: {
       return `${playerName} was left with 0 clues!`;
     }
@@ -259,9 +268,10 @@ export function getDiscardText(
 
   const location = slot === null ? "the deck" : `slot #${slot}`;
   const suffix = getDiscardTextSuffix(touched, critical);
+  const timeoutSuffix = action.timeout === true ? " (timed out)" : "";
   const hypoPrefix = hypothetical ? HYPO_PREFIX : "";
 
-  return `${hypoPrefix}${playerName} discards ${cardName} from ${location}${suffix}`;
+  return `${hypoPrefix}${playerName} discards ${cardName} from ${location}${suffix}${timeoutSuffix}`;
 }
 
 function getDiscardTextSuffix(touched: boolean, critical: boolean): string {
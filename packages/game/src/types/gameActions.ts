@@ -83,6 +83,12 @@ export interface ActionDiscard {
   readonly rank: Rank | -1;
 
   readonly failed: boolean;
+
+  /**
+   * Only present in speedruns with a turn time limit. True if the card was automatically discarded
+   * because the player ran out of time.
+   */
+  readonly timeout?: boolean;
 }
 
 export interface ActionDraw {
@@ -1,10 +1,12 @@
 // The schema in this file corresponds to "database_schema.sql".
 
+import type { AnyPgColumn } from "drizzle-orm/pg-core";
 import {
   boolean,
   doublePrecision,
   index,
   integer,
+  jsonb,
   pgTable,
   serial,
   smallint,
@@ -24,6 +26,9 @@ export const usersTable = pgTable("users", {
   datetimeLastLogin: timestamp("datetime_last_login", { withTimezone: true })
     .notNull()
     .defaultNow(),
+  mergedInto: integer("merged_into").references(
+    (): AnyPgColumn => usersTable.id,
+  ),
 });
 
 export const userSettingsTable = pgTable("user_settings", {
@@ -86,8 +91,22 @@ export const userSettingsTable = pgTable("user_settings", {
     .default(5),
 });
 
+export const userProfilesTable = pgTable("user_profiles", {
+  userID: integer("user_id")
+    .primaryKey()
+    .references(() => usersTable.id),
+  pronouns: text("pronouns").notNull().default(""),
+
+  /** The maximum length is enforced in Golang. */
+  bio: text("bio").notNull().default(""),
+
+  favoriteVariantID: smallint("favorite_variant_id"),
+});
+
 // TODO: user_stats
 
+// TODO: user_ratings
+
 export const userFriendsTable = pgTable("user_friends", {
   userID: integer("user_id")
     .notNull()
@@ -117,6 +136,7 @@ export const gamesTable = pgTable("games", {
   timeBase: integer("time_base").notNull(),
   timePerTurn: integer("time_per_turn").notNull(),
   speedrun: boolean("speedrun").notNull(),
+  turnTimeLimit: boolean("turn_time_limit").notNull().default(false),
   cardCycle: boolean("card_cycle").notNull(),
   deckPlays: boolean("deck_plays").notNull(),
   emptyClues: boolean("empty_clues").notNull(),
@@ -125,9 +145,16 @@ export const gamesTable = pgTable("games", {
   allOrNothing: boolean("all_or_nothing").notNull(),
   detrimentalCharacters: boolean("detrimental_characters").notNull(),
 
+  /** Null if every character was allowed. */
+  allowedCharacters: integer("allowed_characters").array(),
+
   seed: text("seed").notNull(),
   score: smallint("score").notNull(),
   numTurns: smallint("num_turns").notNull(),
+
+  /** Null for games that were played before the column was added. */
+  numStrikes: smallint("num_strikes"),
+
   endCondition: smallint("end_condition").notNull(),
   datetimeStarted: timestamp("datetime_started", {
     withTimezone: true,
@@ -135,6 +162,10 @@ export const gamesTable = pgTable("games", {
   datetimeFinished: timestamp("datetime_finished", {
     withTimezone: true,
   }).notNull(),
+  datetimeDeleted: timestamp("datetime_deleted", {
+    withTimezone: true,
+  }),
+  deletedBy: integer("deleted_by").references(() => usersTable.id),
 });
 
 export const gameParticipantsTable = pgTable("game_participants", {
@@ -147,7 +178,10 @@ export const gameParticipantsTable = pgTable("game_participants", {
     .references(() => usersTable.id),
   seat: smallint("seat").notNull(),
   characterAssignment: smallint("character_assignment").notNull(),
-  characterMetadata: smallint("character_metadata").notNull(),
+  characterMetadata: jsonb("character_metadata").notNull().default({}),
+  datetimeDisconnected: timestamp("datetime_disconnected", {
+    withTimezone: true,
+  }),
 });
 
 // TODO: game_participants
@@ -156,8 +190,26 @@ export const gameParticipantsTable = pgTable("game_participants", {
 
 // TODO: game_actions
 
+// TODO: game_decks
+
+// TODO: hypotheticals
+
+// TODO: hypothetical_actions
+
+// TODO: game_checkpoints
+
+// TODO: replay_bookmarks
+
+// TODO: campaigns
+
+// TODO: campaign_members
+
+// TODO: campaign_games
+
 // TODO: game_tags
 
+// TODO: game_events
+
 // TODO: variant_stats
 
 export const chatLogTable = pgTable(
@@ -235,3 +287,52 @@ export const mutedIPsTable = pgTable("muted_ips", {
     .notNull()
     .defaultNow(),
 });
+
+export const modLogTable = pgTable(
+  "mod_log",
+  {
+    id: serial("id").primaryKey(),
+
+    /** 0 is the server (e.g. an automatic ban). */
+    moderatorID: integer("moderator_id").notNull(),
+
+    targetUserID: integer("target_user_id").notNull(),
+    action: text("action").notNull(),
+    reason: text("reason").notNull(),
+    datetimeCreated: timestamp("datetime_created", { withTimezone: true })
+      .notNull()
+      .defaultNow(),
+  },
+  (table) => ({
+    modLogIndexTargetUserID: index("mod_log_index_target_user_id").on(
+      table.targetUserID,
+    ),
+    modLogIndexModeratorID: index("mod_log_index_moderator_id").on(
+      table.moderatorID,
+    ),
+  }),
+);
+
+export const rateLimitsTable = pgTable(
+  "rate_limits",
+  {
+    id: serial("id").primaryKey(),
+    userID: integer("user_id")
+      .notNull()
+      .references(() => usersTable.id),
+    action: text("action").notNull(),
+    datetimeCreated: timestamp("datetime_created", { withTimezone: true })
+      .notNull()
+      .defaultNow(),
+  },
+  (table) => ({
+    rateLimitsIndexUserIDAction: index("rate_limits_index_user_id_action").on(
+      table.userID,
+      table.action,
+      table.datetimeCreated,
+    ),
+    rateLimitsIndexDatetimeCreated: index(
+      "rate_limits_index_datetime_created",
+    ).on(table.datetimeCreated),
+  }),
+);
@@ -33,21 +33,25 @@ fi
 # - games
 # - game_participants
 # - game_actions
+# - game_decks
 # - seeds
 
 PGPASSWORD="$DB_PASSWORD" psql --host="$DB_HOST" --port="$DB_PORT" --username="$DB_USER" --dbname="$DB_NAME" << EOF
 UPDATE users SET last_ip='0.0.0.0';
 DELETE FROM user_settings;
 DELETE FROM user_stats;
+DELETE FROM user_ratings;
 DELETE FROM user_friends;
 DELETE FROM user_reverse_friends;
 DELETE FROM game_participant_notes;
 DELETE FROM game_tags;
+DELETE FROM hypotheticals;
 DELETE FROM variant_stats;
 DELETE FROM chat_log;
 DELETE FROM chat_log_pm;
 DELETE FROM banned_ips;
 DELETE FROM muted_ips;
+DELETE FROM mod_log;
 UPDATE users SET username = CONCAT('anon_user_', id);
 UPDATE users SET password_hash = '';
 UPDATE users SET old_password_hash = '';
@@ -0,0 +1,76 @@
+#!/usr/bin/env python3
+
+# Converts the "character_metadata" column of the "game_participants" table from an integer to a
+# JSON object. Existing values are stored under the "legacy" key so that old replays still load.
+# (The server also tolerates bare integers, so this is safe to run while the server is online.)
+
+# The "dotenv" module does not work in Python 2
+import sys
+
+if sys.version_info < (3, 0):
+    print("This script requires Python 3.x.")
+    sys.exit(1)
+
+# Imports
+import os
+import dotenv
+import psycopg2
+
+# Import environment variables
+dotenv.load_dotenv(dotenv.find_dotenv())
+
+# Variables
+user = os.getenv("DB_USER")
+password = os.getenv("DB_PASSWORD")
+host = os.getenv("DB_HOST")
+if host == "":
+    host = "localhost"
+port = os.getenv("DB_PORT")
+if port == "":
+    port = "5432"
+database = os.getenv("DB_NAME")
+
+# Connect to the PostgreSQL database
+conn = psycopg2.connect(
+    host=host,
+    port=port,
+    user=user,
+    password=password,
+    database=database,
+)
+
+# Check to see if the column has already been migrated
+cursor = conn.cursor()
+cursor.execute(
+    """
+    SELECT data_type
+    FROM information_schema.columns
+    WHERE table_name = 'game_participants'
+        AND column_name = 'character_metadata'
+    """
+)
+row = cursor.fetchone()
+cursor.close()
+if row is None:
+    print('The "character_metadata" column does not exist.')
+    sys.exit(1)
+if row[0] == "jsonb":
+    print('The "character_metadata" column has already been migrated.')
+    sys.exit(0)
+
+# Convert the column
+cursor = conn.cursor()
+cursor.execute(
+    """
+    ALTER TABLE game_participants
+    ALTER COLUMN character_metadata TYPE JSONB
+        USING JSONB_BUILD_OBJECT('legacy', character_metadata),
+    ALTER COLUMN character_metadata SET DEFAULT '{}'
+    """
+)
+cursor.close()
+
+conn.commit()
+conn.close()
+
+print('Migrated the "character_metadata" column to JSON.')
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+var (
+	abandonedGameTimeout time.Duration
+)
+
+func abandonedGamesInit() {
+	abandonedGameTimeout = DefaultAbandonedGameTimeout
+	timeoutString := os.Getenv("ABANDONED_GAME_TIMEOUT")
+	if len(timeoutString) != 0 {
+		if v, err := strconv.Atoi(timeoutString); err != nil {
+			logger.Fatal("Failed to convert the \"ABANDONED_GAME_TIMEOUT\" environment variable " +
+				"to a number.")
+			return
+		} else {
+			abandonedGameTimeout = time.Minute * time.Duration(v)
+		}
+	}
+
+	if abandonedGameTimeout <= 0 {
+		logger.Info("The \"ABANDONED_GAME_TIMEOUT\" environment variable is 0; " +
+			"abandoned games will not be terminated.")
+		return
+	}
+
+	go abandonedGamesSweep()
+}
+
+// abandonedGamesSweep is meant to be called in a new goroutine
+// It periodically terminates the ongoing games that every player has left
+func abandonedGamesSweep() {
+	ctx := NewMiscContext("abandonedGamesSweep")
+
+	for {
+		time.Sleep(AbandonedGameSweepInterval)
+
+		for _, tableID := range tables.FindAbandoned(ctx, abandonedGameTimeout) {
+			t, exists := getTableAndLock(ctx, nil, tableID, true, true)
+			if !exists {
+				continue
+			}
+
+			// Someone could have reconnected in the meantime
+			if t.IsAbandoned(abandonedGameTimeout) {
+				t.EndAbandoned(ctx)
+			}
+
+			t.Unlock(ctx)
+		}
+	}
+}
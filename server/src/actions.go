@@ -30,6 +30,7 @@ type ActionDiscard struct {
 	SuitIndex   int    `json:"suitIndex"`
 	Rank        int    `json:"rank"`
 	Failed      bool   `json:"failed"`
+	Timeout     bool   `json:"timeout,omitempty"` // Only in games with a turn time limit
 }
 
 type ActionDraw struct {
@@ -114,9 +114,10 @@ func getEquivalentPlayer(t *Table, userID int) *GamePlayer {
 	if playerIndex > -1 {
 		// The action is going to be sent to one of the active players
 		return g.Players[playerIndex]
-	} else if spectatorIndex > -1 && t.Spectators[spectatorIndex].ShadowingPlayerIndex != -1 {
-		// The action is going to be sent to a spectator that is shadowing one of the active players
-		return g.Players[t.Spectators[spectatorIndex].ShadowingPlayerIndex]
+	} else if spectatorIndex > -1 {
+		// The action is going to be sent to a spectator that might be shadowing one of the active
+		// players (this will be nil if they are not)
+		return t.Spectators[spectatorIndex].GetShadowedPlayer(g)
 	}
 
 	// The action is going to be sent to a spectator that can see every hand
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/Hanabi-Live/hanabi-live/logger"
 	"github.com/gin-gonic/gin"
 )
 
@@ -232,3 +233,83 @@ func apiGames(c *gin.Context, rowCount int, gameIDs []int, orderBy string) {
 
 	c.JSON(http.StatusOK, out)
 }
+
+type APITurnTimingsAnswer struct {
+	Info string       `json:"info"`
+	Rows []TurnTiming `json:"rows"`
+}
+
+// Returns the time that was spent on each action of a game
+//   URL: /api/v1/games/:id/timings
+func apiGameTurnTimings(c *gin.Context) {
+	if apiCheckIPBanned(c) {
+		return
+	}
+
+	// Validate the id
+	var id int
+	if v, err := httpGetIntVariable(c, "id"); err != nil {
+		c.JSON(http.StatusBadRequest, APITurnTimingsAnswer{
+			Info: "Missing valid game ID",
+			Rows: nil,
+		})
+		return
+	} else {
+		id = v
+	}
+
+	var timings []TurnTiming
+	if v, err := models.GameActions.GetTurnTimings(id); err != nil {
+		logger.Error("Failed to get the turn timings for game " + strconv.Itoa(id) + ": " +
+			err.Error())
+		c.JSON(http.StatusInternalServerError, APITurnTimingsAnswer{})
+		return
+	} else {
+		timings = v
+	}
+
+	c.JSON(http.StatusOK, APITurnTimingsAnswer{
+		Info: "The think time of each action is in milliseconds",
+		Rows: timings,
+	})
+}
+
+type APIGameIDsAnswer struct {
+	Info string `json:"info"`
+	Rows []int  `json:"rows"`
+}
+
+// Returns the IDs of the games that the given player[s] played in together, most recent first
+//   URL: /api/v1/games-with/:player1 [/:player2...]
+//
+//   Params
+//   exact=1: only games where these were the only players
+func apiGamesWithPlayers(c *gin.Context) {
+	if apiCheckIPBanned(c) {
+		return
+	}
+
+	// Parse the player name(s) from the URL
+	var playerIDs []int
+	if v1, _, ok := httpParsePlayerNames(c); !ok {
+		return
+	} else {
+		playerIDs = v1
+	}
+
+	exact := c.Query("exact") == "1"
+
+	var gameIDs []int
+	if v, err := models.GameParticipants.FindGamesWithUsers(playerIDs, exact); err != nil {
+		logger.Error("Failed to find the games with a set of players: " + err.Error())
+		c.JSON(http.StatusInternalServerError, APIGameIDsAnswer{})
+		return
+	} else {
+		gameIDs = v
+	}
+
+	c.JSON(http.StatusOK, APIGameIDsAnswer{
+		Info: "Params: exact=0|1 (only include games without any other players)",
+		Rows: gameIDs,
+	})
+}
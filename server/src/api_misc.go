@@ -125,7 +125,8 @@ func apiGetLimits(c *gin.Context) (idStart, idEnd *int) {
 // Builds an SQL subquery
 // Returns the WHERE part, the ORDER BY part and the args
 func apiBuildSubquery(params APIQueryVars) (string, string, string, []interface{}) {
-	where := ""
+	// Games that were deleted by a moderator are never shown
+	where := "games.datetime_deleted IS NULL AND "
 	args := []interface{}{}
 
 	for _, filter := range params.Filters {
@@ -164,6 +165,9 @@ func apiSetRoutes(httpRouter *gin.Engine) {
 	// List of games by variant
 	httpRouter.GET(api+"/variants/:id", apiVariantsSingle)
 
+	// Best score of every team for a variant
+	httpRouter.GET(api+"/variants/:id/leaderboard", apiVariantsLeaderboard)
+
 	// List of games played by player[s]
 	httpRouter.GET(api+"/history/:player1", apiHistory)
 	httpRouter.GET(api+"/history/:player1/:player2", apiHistory)
@@ -180,11 +184,22 @@ func apiSetRoutes(httpRouter *gin.Engine) {
 	httpRouter.GET(api+"/history-full/:player1/:player2/:player3/:player4/:player5", apiFullDataHistory)
 	httpRouter.GET(api+"/history-full/:player1/:player2/:player3/:player4/:player5/:player6", apiFullDataHistory)
 
+	// IDs of the games played by a set of players (with ?exact=1, no other players)
+	httpRouter.GET(api+"/games-with/:player1", apiGamesWithPlayers)
+	httpRouter.GET(api+"/games-with/:player1/:player2", apiGamesWithPlayers)
+	httpRouter.GET(api+"/games-with/:player1/:player2/:player3", apiGamesWithPlayers)
+	httpRouter.GET(api+"/games-with/:player1/:player2/:player3/:player4", apiGamesWithPlayers)
+	httpRouter.GET(api+"/games-with/:player1/:player2/:player3/:player4/:player5", apiGamesWithPlayers)
+	httpRouter.GET(api+"/games-with/:player1/:player2/:player3/:player4/:player5/:player6", apiGamesWithPlayers)
+
 	// List of games played by seed
 	httpRouter.GET(api+"/seed/:seed", apiSeed)
 
 	// List of games played by seed (full data)
 	httpRouter.GET(api+"/seed-full/:seed", apiFullDataSeed)
+
+	// Time spent on each action of a game
+	httpRouter.GET(api+"/games/:id/timings", apiGameTurnTimings)
 }
 
 // Checks if a string contains a numeric value
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/Hanabi-Live/hanabi-live/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v4"
 )
@@ -133,6 +134,61 @@ func apiVariantsSingle(c *gin.Context) {
 	c.JSON(http.StatusOK, out)
 }
 
+type APIVariantLeaderboardAnswer struct {
+	Info string           `json:"info"`
+	Rows []LeaderboardRow `json:"rows"`
+}
+
+// Returns the best game of each team for the given variant
+//   URL: /api/v1/variants/:id/leaderboard
+//
+//   Columns
+//   id                int
+//   num_players       int
+//   score             int
+//   users             string
+//   duration          int (in seconds)
+//   datetime          string
+//   seed              string
+//
+//   Order
+//   score (descending), then duration (ascending)
+func apiVariantsLeaderboard(c *gin.Context) {
+	if apiCheckIPBanned(c) {
+		return
+	}
+
+	// Validate the id
+	var id int
+	if v, err := apiGetVariantIDFromParam(c); err != nil {
+		c.JSON(http.StatusBadRequest, APIVariantLeaderboardAnswer{
+			Info: "Missing valid variant ID",
+			Rows: nil,
+		})
+		return
+	} else {
+		id = v
+	}
+
+	page := apiGetPage(c)
+	size := apiGetSize(c)
+
+	var dbRows []LeaderboardRow
+	if v, err := models.Games.GetVariantLeaderboard(id, page*size, size); err != nil {
+		logger.Error("Failed to get the leaderboard for variant " + strconv.Itoa(id) + ": " +
+			err.Error())
+		c.JSON(http.StatusInternalServerError, APIVariantLeaderboardAnswer{})
+		return
+	} else {
+		dbRows = v
+	}
+
+	c.JSON(http.StatusOK, APIVariantLeaderboardAnswer{
+		Info: "Params: size=0...100, page=0...",
+		Rows: dbRows,
+	})
+}
+
 // Returns valid variant ID
 func apiGetVariantIDFromParam(c *gin.Context) (int, error) {
 	if v, err := httpGetIntVariable(c, "id"); err != nil {
@@ -13,6 +13,7 @@ type Card struct {
 	Discarded bool
 	Played    bool
 	Failed    bool
+	TimedOut  bool // Discarded automatically because the player ran out of time
 	// After a player takes their final turn,
 	// all of the remaining cards in their hand are marked with the following bool
 	CannotBePlayed   bool
@@ -29,6 +30,7 @@ func NewCard(suit int, rank int) *Card {
 		Discarded:        false,
 		Played:           false,
 		Failed:           false,
+		TimedOut:         false,
 		CannotBePlayed:   false,
 		InsistentTouched: false,
 	}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path"
 	"strconv"
+	"strings"
 
 	"github.com/Hanabi-Live/hanabi-live/logger"
 )
@@ -108,6 +109,32 @@ func charactersInit() {
 	}
 }
 
+// getCharacterPool returns the names of the characters that can be dealt for a set of options
+// (in the same order as the "characterNames" slice)
+func getCharacterPool(options *Options) []string {
+	if len(options.AllowedCharacters) == 0 {
+		return characterNames
+	}
+
+	characterPool := make([]string, 0, len(options.AllowedCharacters))
+	for _, characterName := range characterNames {
+		if intInSlice(characters[characterName].ID, options.AllowedCharacters) {
+			characterPool = append(characterPool, characterName)
+		}
+	}
+
+	return characterPool
+}
+
+// getCharacterPoolDescription returns the names of the allowed characters for use in chat messages
+func getCharacterPoolDescription(options *Options) string {
+	if len(options.AllowedCharacters) == 0 {
+		return "All"
+	}
+
+	return strings.Join(getCharacterPool(options), ", ")
+}
+
 func charactersGenerate(g *Game) {
 	if !g.Options.DetrimentalCharacters {
 		return
@@ -137,20 +164,27 @@ func charactersGenerate(g *Game) {
 
 	// This is not a replay,
 	// so we must generate new random character selections based on the game's seed
-	setSeed(g.Seed) // Seed the random number generator
+	// We use a dedicated random number generator (instead of the global one) so that other
+	// goroutines cannot change the character assignments for the seed
+	r := rand.New(rand.NewSource(getSeedInt64(g.Seed))) // nolint: gosec
+
+	// It is assumed that the pool has already been validated with the "isCharacterPoolValid()"
+	// function, or else the loop below might never finish
+	characterPool := getCharacterPool(g.Options)
+
+	// The hard-coded debug characters might not be in a restricted pool
+	useDebugCharacters := len(g.Options.AllowedCharacters) == 0
 
 	for i, p := range g.Players {
 		// Set the character
-		if stringInSlice(p.Name, debugUsernames) {
+		if useDebugCharacters && stringInSlice(p.Name, debugUsernames) {
 			// Hard-code some character assignments for testing purposes
 			p.Character = debugCharacters[i]
 		} else {
 			for {
 				// Get a random character assignment
-				// We do not have to seed the PRNG,
-				// since that was done just a moment ago when the deck was shuffled
-				randomIndex := rand.Intn(len(characterNames)) // nolint: gosec
-				p.Character = characterNames[randomIndex]
+				randomIndex := r.Intn(len(characterPool))
+				p.Character = characterPool[randomIndex]
 
 				// Check to see if any other players have this assignment already
 				alreadyAssigned := false
@@ -182,21 +216,21 @@ func charactersGenerate(g *Game) {
 
 		// Specific characters also have secondary attributes that are stored in the character
 		// metadata field
-		if stringInSlice(p.Name, debugUsernames) {
+		if useDebugCharacters && stringInSlice(p.Name, debugUsernames) {
 			p.CharacterMetadata = debugCharacterMetadata[i]
 		} else {
 			if p.Character == "Fuming" { // 0
 				// A random number from 0 to the number of colors in this variant
-				p.CharacterMetadata = rand.Intn(len(variant.ClueColors)) // nolint: gosec
+				p.CharacterMetadata = r.Intn(len(variant.ClueColors))
 			} else if p.Character == "Dumbfounded" { // 1
 				// A random number from 1 to 5
-				p.CharacterMetadata = rand.Intn(4) + 1 // nolint: gosec
+				p.CharacterMetadata = r.Intn(4) + 1
 			} else if p.Character == "Inept" { // 2
 				// A random number from 0 to the number of colors in this variant
-				p.CharacterMetadata = rand.Intn(len(variant.ClueColors)) // nolint: gosec
+				p.CharacterMetadata = r.Intn(len(variant.ClueColors))
 			} else if p.Character == "Awkward" { // 3
 				// A random number from 1 to 5
-				p.CharacterMetadata = rand.Intn(4) + 1 // nolint: gosec
+				p.CharacterMetadata = r.Intn(4) + 1
 			}
 		}
 	}
@@ -55,9 +55,10 @@ func chatTags(ctx context.Context, s *Session, d *CommandData, t *Table, cmd str
 		return
 	}
 
-	// Get the tags from the database
+	// Get the tags of this user from the database
+	// (tags are per-user, so the tags of other users are not shown)
 	var tags []string
-	if v, err := models.GameTags.GetAll(t.ExtraOptions.DatabaseID); err != nil {
+	if v, err := models.GameTags.GetByGame(t.ExtraOptions.DatabaseID, s.UserID); err != nil {
 		logger.Error("Failed to get the tags for game ID " +
 			strconv.Itoa(t.ExtraOptions.DatabaseID) + ": " + err.Error())
 		s.Error(DefaultErrorMsg)
@@ -67,8 +68,8 @@ func chatTags(ctx context.Context, s *Session, d *CommandData, t *Table, cmd str
 	}
 
 	if len(tags) == 0 {
-		msg := "There are not yet any tags for this game."
-		chatServerSend(ctx, msg, d.Room, d.NoTablesLock)
+		msg := "You have not added any tags to this game yet."
+		chatServerSendPM(s, msg, d.Room)
 		return
 	}
 
@@ -76,10 +77,10 @@ func chatTags(ctx context.Context, s *Session, d *CommandData, t *Table, cmd str
 	// lowercase
 	sort.Strings(tags)
 
-	msg := "The list of tags for this game are as follows:"
-	chatServerSend(ctx, msg, d.Room, d.NoTablesLock)
+	msg := "The list of your tags for this game are as follows:"
+	chatServerSendPM(s, msg, d.Room)
 	for i, tag := range tags {
 		msg := strconv.Itoa(i+1) + ") " + tag
-		chatServerSend(ctx, msg, d.Room, d.NoTablesLock)
+		chatServerSendPM(s, msg, d.Room)
 	}
 }
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+// checkpointsSweep is meant to be called in a new goroutine
+// It periodically saves every ongoing game to the database so that the games can be restored with
+// the "restoreCheckpoints()" function if the server crashes
+// (graceful restarts use the "serializeTables()" function instead)
+func checkpointsSweep() {
+	ctx := NewMiscContext("checkpointsSweep")
+
+	for {
+		time.Sleep(CheckpointInterval)
+
+		tableIDs := make([]uint64, 0)
+		for _, t := range tables.GetList(true) {
+			t.Lock(ctx)
+			if !t.Running || t.Replay || t.Deleted || t.ExtraOptions.NoWriteToDatabase {
+				t.Unlock(ctx)
+				continue
+			}
+
+			// Record the time on the current turn so that it is still charged after a crash
+			t.Game.SaveTurnTime()
+
+			// The snapshot must be taken while the table is locked so that it is never in the
+			// middle of an action
+			// The checkpoint must also be written while the table is locked, or else the game could
+			// end (and delete its checkpoint) before the snapshot of the ongoing game is written
+			var tableJSON []byte
+			if v, err := json.Marshal(t); err != nil {
+				logger.Error("Failed to marshal table " + strconv.FormatUint(t.ID, 10) + ": " +
+					err.Error())
+				t.Unlock(ctx)
+				continue
+			} else {
+				tableJSON = v
+			}
+
+			if err := models.Games.SaveCheckpoint(t.ID, SerializedState{
+				TableJSON:     tableJSON,
+				DatetimeSaved: time.Now(),
+			}); err != nil {
+				logger.Error("Failed to save the checkpoint for table " +
+					strconv.FormatUint(t.ID, 10) + ": " + err.Error())
+				t.Unlock(ctx)
+				continue
+			}
+			t.Unlock(ctx)
+
+			tableIDs = append(tableIDs, t.ID)
+		}
+
+		// Games that have ended since the last sweep should not be restored
+		// (checkpoints are also deleted at the end of a game, but that can fail)
+		if err := models.Games.DeleteCheckpointsExcept(tableIDs); err != nil {
+			logger.Error("Failed to delete the old checkpoints: " + err.Error())
+		}
+	}
+}
+
+// restoreCheckpoints recreates the ongoing tables that were saved to the database by the
+// "checkpointsSweep()" function
+// This must be called after the "restoreTables()" function, since tables that were serialized
+// during a graceful restart are more recent than their checkpoints
+func restoreCheckpoints() {
+	ctx := NewMiscContext("restoreCheckpoints")
+
+	// We first acquire the tables lock so that we can safely modify the tables map
+	tables.Lock(ctx)
+	defer tables.Unlock(ctx)
+
+	var tableIDs []uint64
+	if v, err := models.Games.GetCheckpointTableIDs(); err != nil {
+		logger.Fatal("Failed to get the table IDs of the checkpoints: " + err.Error())
+		return
+	} else {
+		tableIDs = v
+	}
+
+	numTablesRestored := 0
+	for _, tableID := range tableIDs {
+		if _, ok := tables.Get(tableID, false); ok {
+			// This table was already restored from a file
+			continue
+		}
+
+		var state SerializedState
+		if v, err := models.Games.LoadCheckpoint(tableID); err != nil {
+			logger.Error("Failed to load the checkpoint for table " +
+				strconv.FormatUint(tableID, 10) + ": " + err.Error())
+			continue
+		} else {
+			state = v
+		}
+
+		t := &Table{} // We must initialize the table for "Unmarshal()" to work
+		if err := json.Unmarshal(state.TableJSON, t); err != nil {
+			logger.Error("Failed to unmarshal the checkpoint for table " +
+				strconv.FormatUint(tableID, 10) + ": " + err.Error())
+			continue
+		}
+
+		logger.Info("Restoring table " + strconv.FormatUint(tableID, 10) + " from the checkpoint " +
+			"saved at " + formatTimestampUnix(state.DatetimeSaved) + ".")
+		restoreTableState(ctx, t)
+		numTablesRestored++
+	}
+
+	if numTablesRestored > 0 {
+		msg := "Restored " + strconv.Itoa(numTablesRestored) + " table"
+		if numTablesRestored >= 2 {
+			msg += "s"
+		}
+		msg += " from checkpoints."
+		logger.Info(msg)
+	}
+}
@@ -42,9 +42,11 @@ type CommandData struct {
 	ShadowingPlayerIndex int `json:"shadowingPlayerIndex"`
 
 	// replayCreate
-	Source     string    `json:"source"`
-	GameJSON   *GameJSON `json:"gameJSON"`
-	Visibility string    `json:"visibility"`
+	Source   string    `json:"source"`
+	GameJSON *GameJSON `json:"gameJSON"`
+	// Either "solo" or "shared" for replays
+	// Either "" (for everyone) or "friends" for new tables
+	Visibility string `json:"visibility"`
 
 	// sharedReplay
 	Segment int    `json:"segment"`
@@ -69,6 +71,13 @@ type CommandData struct {
 	// restart
 	HidePregame bool `json:"hidePregame"`
 
+	// tableRematch
+	SameSeed bool `json:"sameSeed"`
+
+	// replayBookmark
+	BookmarkID int    `json:"bookmarkID"`
+	Comment    string `json:"comment"`
+
 	// Used internally
 	// (a tag of "-" means that the JSON encoder will ignore the field)
 	Username string `json:"-"` // Used to mark the username of a chat message
@@ -86,6 +95,16 @@ type CommandData struct {
 	// More remake table shenanigans
 	PasswordHash   string `json:"-"`
 	BypassPassword bool   `json:"-"`
+	// Restarts do not count towards the table creation rate limit
+	// (since the new table is hidden and replaces the old one)
+	BypassRateLimit bool `json:"-"`
+	// Rematches on the same seed are allowed to re-use a seed that the server generated
+	AllowGeneratedSeed bool `json:"-"`
+	// True if the server is discarding for a player who ran out of time on a turn time limit
+	TurnTimeLimitExpired bool `json:"-"`
+	// True if the server is ending the game because of a resignation vote or because every player
+	// abandoned it
+	EndedByServer bool `json:"-"`
 }
 
 var (
@@ -106,8 +125,10 @@ func commandInit() {
 	commandMap["tableStart"] = commandTableStart
 	commandMap["tableTerminate"] = commandTableTerminate
 	commandMap["tableVoteForTermination"] = commandTableVoteForTermination
+	commandMap["tableVoteForResignation"] = commandTableVoteForResignation
 	commandMap["tableSpectate"] = commandTableSpectate
 	commandMap["tableRestart"] = commandTableRestart
+	commandMap["tableRematch"] = commandTableRematch
 	commandMap["tableUpdate"] = commandTableUpdate
 	commandMap["tableSuggest"] = commandTableSuggest
 
@@ -129,6 +150,7 @@ func commandInit() {
 	commandMap["historyGetSeed"] = commandHistoryGetSeed
 	commandMap["historyFriendsGet"] = commandHistoryFriendsGet
 	commandMap["replayCreate"] = commandReplayCreate
+	commandMap["replayImport"] = commandReplayImport
 	commandMap["tagSearch"] = commandTagSearch
 
 	// Game and replay commands
@@ -146,4 +168,7 @@ func commandInit() {
 
 	// Replay commands
 	commandMap["replayAction"] = commandReplayAction
+	commandMap["replayBookmark"] = commandReplayBookmark
+	commandMap["replayBookmarkEdit"] = commandReplayBookmarkEdit
+	commandMap["replayBookmarkDelete"] = commandReplayBookmarkDelete
 }
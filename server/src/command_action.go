@@ -132,7 +132,11 @@ func action(ctx context.Context, s *Session, d *CommandData, t *Table, p *GamePl
 	// Adjust the timer for the player that just took their turn
 	// (if the game is over now due to a player running out of time, we do not need to adjust the
 	// timer because we already set it to 0 in the "checkTimer" function)
+	var actionTime time.Duration
 	if d.Type != ActionTypeEndGame && d.Type != ActionTypeEndGameByVote {
+		actionTime = g.TurnTimeBeforePause + time.Since(g.DatetimeTurnBegin)
+		g.TurnTimeBeforePause = 0
+
 		p.Time -= time.Since(g.DatetimeTurnBegin)
 		// (in non-timed games,
 		// "Time" will decrement into negative numbers to show how much time they are taking)
@@ -145,6 +149,12 @@ func action(ctx context.Context, s *Session, d *CommandData, t *Table, p *GamePl
 		g.DatetimeTurnBegin = time.Now()
 	}
 
+	// Record the time for the action(s) that were just added to the action log
+	// (the time for a "game over" action is 0)
+	for len(g.ActionTimes) < len(g.Actions2) {
+		g.ActionTimes = append(g.ActionTimes, actionTime)
+	}
+
 	// If a player has just taken their final turn,
 	// mark all of the cards in their hand as not able to be played
 	// (but do not do this if we are in an end game that has a custom amount of turns)
@@ -208,6 +218,11 @@ func action(ctx context.Context, s *Session, d *CommandData, t *Table, p *GamePl
 	// Send everyone new clock values
 	t.NotifyTime()
 
+	if t.Options.TurnTimeLimit && !t.ExtraOptions.NoWriteToDatabase {
+		// Start the clock for the next player
+		g.StartTurnTimeLimit(ctx, TurnTimeLimit)
+	}
+
 	if t.Options.Timed && !t.ExtraOptions.NoWriteToDatabase {
 		// Start the function that will check to see if the current player has run out of time
 		// (since it just got to be their turn)
@@ -266,6 +281,21 @@ func commandActionDiscard(s *Session, d *CommandData, g *Game, p *GamePlayer) bo
 		return false
 	}
 
+	// Validate that timeout discards only happen in games with a turn time limit
+	if d.TurnTimeLimitExpired && !g.Options.TurnTimeLimit {
+		s.Warning("You cannot time out in a game without a turn time limit.")
+		g.InvalidActionOccurred = true
+		return false
+	}
+
+	// Validate that timeout discards only come from the server
+	// (clients cannot set "TurnTimeLimitExpired", since it is ignored by the JSON decoder)
+	if d.Value == DiscardValueTimeout && !d.TurnTimeLimitExpired {
+		s.Warning("That is not a valid discard value.")
+		g.InvalidActionOccurred = true
+		return false
+	}
+
 	// Validate that the team is not at the maximum amount of clues
 	if variant.AtMaxClueTokens(g.ClueTokens) {
 		s.Warning("You cannot discard while the team has " + strconv.Itoa(MaxClueNum) + " clues.")
@@ -281,6 +311,7 @@ func commandActionDiscard(s *Session, d *CommandData, g *Game, p *GamePlayer) bo
 
 	g.ClueTokens++
 	c := p.RemoveCard(d.Target)
+	c.TimedOut = d.TurnTimeLimitExpired
 	p.DiscardCard(c)
 	p.DrawCard()
 
@@ -393,6 +424,8 @@ func commandActionEndGame(s *Session, d *CommandData, g *Game, p *GamePlayer) bo
 		d.Value != EndConditionTerminatedByPlayer &&
 		d.Value != EndConditionTerminatedByVote &&
 		d.Value != EndConditionIdleTimeout &&
+		d.Value != EndConditionAbandoned &&
+		d.Value != EndConditionResigned &&
 		d.Value != EndConditionAllOrNothingFail {
 
 		s.Warning("That is not a valid value for the end game action.")
@@ -400,6 +433,15 @@ func commandActionEndGame(s *Session, d *CommandData, g *Game, p *GamePlayer) bo
 		return false
 	}
 
+	// Resigned and abandoned games are excluded from ratings and statistics,
+	// so players must not be able to end a game in this way by themselves
+	// (clients cannot set "EndedByServer", since it is ignored by the JSON decoder)
+	if (d.Value == EndConditionResigned || d.Value == EndConditionAbandoned) && !d.EndedByServer {
+		s.Warning("That is not a valid value for the end game action.")
+		g.InvalidActionOccurred = true
+		return false
+	}
+
 	// Mark that the game should be ended
 	g.EndCondition = d.Value
 	g.EndPlayer = d.Target
@@ -430,6 +472,18 @@ func commandActionEndGame(s *Session, d *CommandData, g *Game, p *GamePlayer) bo
 			Target: -1,
 			Value:  EndConditionIdleTimeout,
 		}
+	} else if g.EndCondition == EndConditionAbandoned {
+		endGameAction = &GameAction{
+			Type:   ActionTypeEndGame,
+			Target: -1,
+			Value:  EndConditionAbandoned,
+		}
+	} else if g.EndCondition == EndConditionResigned {
+		endGameAction = &GameAction{
+			Type:   ActionTypeEndGameByVote,
+			Target: -1,
+			Value:  EndConditionResigned,
+		}
 	}
 	if endGameAction != nil {
 		g.Actions2 = append(g.Actions2, endGameAction)
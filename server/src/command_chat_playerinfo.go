@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"errors"
+	"math"
 	"strconv"
 
 	"github.com/Hanabi-Live/hanabi-live/logger"
@@ -14,37 +16,31 @@ import (
 //   name: 'Alice',
 // }
 func commandChatPlayerInfo(ctx context.Context, s *Session, d *CommandData) {
-	normalizedUsername := normalizeString(d.Name)
-
-	// Validate that this person exists in the database
-	var user User
-	if exists, v, err := models.Users.GetUserFromNormalizedUsername(
-		normalizedUsername,
-	); err != nil {
-		logger.Error("Failed to validate that \"" + normalizedUsername + "\" " +
-			"exists in the database: " + err.Error())
-		s.Error(DefaultErrorMsg)
-		return
-	} else if !exists {
+	var summary *ProfileSummary
+	if v, err := models.Users.GetProfileSummary(d.Name); errors.Is(err, ErrUserNotFound) {
 		s.Warning("The username of \"" + d.Name + "\" does not exist in the database.")
 		return
-	} else {
-		user = v
-	}
-
-	var numGames int
-	if v, err := models.Games.GetUserNumGames(user.ID, false); err != nil {
-		logger.Error("Failed to get the number of non-speedrun games for player " +
-			"\"" + d.Name + "\": " + err.Error())
+	} else if err != nil {
+		logger.Error("Failed to get the profile summary for player \"" + d.Name + "\": " +
+			err.Error())
 		s.Error("Something went wrong when getting stats. Please contact an administrator.")
 		return
 	} else {
-		numGames = v
+		summary = v
+	}
+
+	lastSeen := formatTimestampUnix(summary.LastSeen)
+	if _, ok := sessions.Get(summary.UserID); ok {
+		lastSeen = "now"
 	}
 
-	msg := "\"" + d.Name + "\" has played " + strconv.Itoa(numGames) + " non-speedrun games. " +
+	msg := "\"" + summary.Username + "\" has played " + strconv.Itoa(summary.NumGames) +
+		" games. " +
+		"Best 3-player score: " + strconv.Itoa(summary.BestScore3) + ". " +
+		"Rating: " + strconv.Itoa(int(math.Round(summary.Rating))) + ". " +
+		"Last seen: " + lastSeen + ". " +
 		"More stats " +
-		"<a href=\"/scores/" + d.Name + "\" target=\"_blank\" rel=\"noopener noreferrer\">" +
+		"<a href=\"/scores/" + summary.Username + "\" target=\"_blank\" rel=\"noopener noreferrer\">" +
 		"here</a>."
 	chatServerSendPM(s, msg, d.Room)
 }
@@ -99,9 +99,13 @@ func getGameInfo1(s *Session, t *Table, playerIndex int, spectatorIndex int) {
 	// Account for if a spectator is shadowing a specific player
 	if playerIndex == -1 && spectatorIndex != -1 && t.Spectators[spectatorIndex].ShadowingPlayerPregameIndex != -1 {
 		sp := t.Spectators[spectatorIndex]
-		ourPlayerIndex = t.GetPlayerIndexFromID(sp.ShadowingPlayerPregameIndex)
 		sp.ShadowingPlayerIndex = t.GetPlayerIndexFromID(sp.ShadowingPlayerPregameIndex)
 		sp.ShadowingPlayerPregameIndex = -1
+		if sp.ShadowingPlayerIndex == -1 {
+			// The player that they were shadowing left the table before the game started,
+			// so fall back to the omniscient view
+			sp.ShadowingPlayerUsername = ""
+		}
 	}
 
 	// Account for if a spectator is shadowing a specific player
@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"strconv"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
 )
 
 // commandGetGameInfo2 provides all of the actions that have happened thus far in the game
@@ -76,6 +78,18 @@ func getGameInfo2(s *Session, t *Table, playerIndex int, spectatorIndex int) {
 	if t.Replay {
 		// Since the game is over, send them the notes from all the players & spectators
 		s.NotifyNoteList(t, -1)
+
+		// Send them the bookmarks from all the users (if this is a game from the database)
+		if t.ExtraOptions.DatabaseID > 0 {
+			if bookmarks, err := models.ReplayBookmarks.GetByGame(
+				t.ExtraOptions.DatabaseID,
+			); err != nil {
+				logger.Error("Failed to get the bookmarks for game " +
+					strconv.Itoa(t.ExtraOptions.DatabaseID) + ": " + err.Error())
+			} else {
+				s.NotifyReplayBookmarks(t, bookmarks)
+			}
+		}
 	} else {
 		// Send them the current connection status of the players
 		s.NotifyConnected(t)
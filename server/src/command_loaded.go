@@ -39,8 +39,15 @@ func commandLoaded(ctx context.Context, s *Session, d *CommandData) {
 	// Set their "present" variable back to true,
 	// which will turn their name from red to black
 	t.Players[playerIndex].Present = true
+	t.Players[playerIndex].DatetimeDisconnected = time.Time{}
 	t.NotifyConnected()
 
+	// If it is their turn in a game with a turn time limit,
+	// their clock was paused while they were disconnected
+	if g.StartedTimer && playerIndex == g.ActivePlayerIndex {
+		g.ResumeTurnTimeLimit(ctx)
+	}
+
 	// Start the timer if this is the first player
 	if !g.StartedTimer && playerIndex == g.ActivePlayerIndex {
 		g.StartedTimer = true
@@ -55,5 +62,8 @@ func commandLoaded(ctx context.Context, s *Session, d *CommandData) {
 			activePlayer := g.Players[g.ActivePlayerIndex]
 			go g.CheckTimer(ctx, activePlayer.Time, g.Turn, g.PauseCount, activePlayer)
 		}
+		if t.Options.TurnTimeLimit && !t.ExtraOptions.NoWriteToDatabase {
+			g.StartTurnTimeLimit(ctx, TurnTimeLimit)
+		}
 	}
 }
@@ -6,6 +6,8 @@ import (
 	"strings"
 	"unicode"
 	"unicode/utf8"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
 )
 
 // commandNote is sent when the user writes a note
@@ -33,9 +35,17 @@ func commandNote(ctx context.Context, s *Session, d *CommandData) {
 	}
 
 	// Validate that it is not a replay
+	// (the exception is a replay of a game from the database,
+	// where the players from the game can edit their own notes)
+	replayPlayerIndex := -1
 	if t.Replay {
-		s.Warning("You can not send a note in a replay.")
-		return
+		if t.ExtraOptions.DatabaseID > 0 && !t.ExtraOptions.SetReplay {
+			replayPlayerIndex = t.GetReplayPlayerIndexFromName(s.Username)
+		}
+		if replayPlayerIndex == -1 {
+			s.Warning("You can not send a note in a replay.")
+			return
+		}
 	}
 
 	// Validate that they are in the game
@@ -47,6 +57,13 @@ func commandNote(ctx context.Context, s *Session, d *CommandData) {
 		return
 	}
 
+	// Validate that the card order is within the bounds of the deck
+	// (the notes for the stack bases come after the notes for the cards in the deck)
+	if d.Order < 0 || d.Order >= t.Game.GetNotesSize() {
+		s.Warning("That is not a valid card order.")
+		return
+	}
+
 	// Truncate long notes
 	// (we do this first to prevent wasting CPU cycles on validating extremely long notes)
 	if len(d.Note) > MaxChatLength {
@@ -81,6 +98,26 @@ func commandNote(ctx context.Context, s *Session, d *CommandData) {
 		return
 	}
 
+	if t.Replay {
+		// The players in a replay are fake players,
+		// so we edit the notes of the player that they represent instead of their spectator notes
+		playerIndex = replayPlayerIndex
+		spectatorIndex = -1
+
+		// Notes from a replay are written to the database immediately
+		if err := models.GameParticipantNotes.Upsert(
+			t.ExtraOptions.DatabaseID,
+			s.UserID,
+			d.Order,
+			d.Note,
+		); err != nil {
+			logger.Error("Failed to update the note for card " + strconv.Itoa(d.Order) +
+				" of game " + strconv.Itoa(t.ExtraOptions.DatabaseID) + ": " + err.Error())
+			s.Error(DefaultErrorMsg)
+			return
+		}
+	}
+
 	note(d, t, playerIndex, spectatorIndex)
 }
 
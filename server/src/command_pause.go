@@ -3,7 +3,6 @@ package main
 import (
 	"context"
 	"strconv"
-	"time"
 )
 
 // commandPause is sent when the user pauses or unpauses the game
@@ -62,6 +61,14 @@ func commandPause(ctx context.Context, s *Session, d *CommandData) {
 			s.Warning("The game is already paused.")
 			return
 		}
+
+		// Only the active player can pause the game
+		// (other players can queue a pause for when it gets to their turn)
+		if g.ActivePlayerIndex != playerIndex {
+			s.Warning("You can only pause the game on your turn. " +
+				"Queue a pause instead to pause it when it gets to your turn.")
+			return
+		}
 	} else if d.Setting == "unpause" {
 		if !g.Paused {
 			s.Warning("The game is not paused, so you cannot unpause.")
@@ -100,19 +107,9 @@ func pause(ctx context.Context, s *Session, d *CommandData, t *Table, playerInde
 	}
 
 	if d.Setting == "pause" {
-		g.Paused = true
-		g.PausePlayerIndex = playerIndex
-		g.PauseCount++
-
-		// Decrement the time that the player has taken so far prior to this pause
-		p.Time -= time.Since(g.DatetimeTurnBegin)
+		g.Pause(playerIndex)
 	} else if d.Setting == "unpause" {
-		g.Paused = false
-		g.PausePlayerIndex = -1
-
-		// Technically, a players turn should not begin when the game is unpaused,
-		// but this variable is only used for decrementing time taken at the end of a player's turn
-		g.DatetimeTurnBegin = time.Now()
+		g.Resume()
 
 		// Restart the function that will check to see if the current player has run out of time
 		// (the old "CheckTimer()" invocation will return and do nothing because the pause count of
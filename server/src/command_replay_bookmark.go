@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+const (
+	MaxReplayBookmarkCommentLength = 300
+)
+
+// commandReplayBookmark is sent when a user bookmarks the current turn of a shared replay
+//
+// Example data:
+//
+//	{
+//	  tableID: 123,
+//	  segment: 5,
+//	  comment: 'This is where Bob should have played the red 2',
+//	}
+func commandReplayBookmark(ctx context.Context, s *Session, d *CommandData) {
+	t, exists := getTableAndLock(ctx, s, d.TableID, !d.NoTableLock, !d.NoTablesLock)
+	if !exists {
+		return
+	}
+	if !d.NoTableLock {
+		defer t.Unlock(ctx)
+	}
+
+	// Validate that this is a shared replay of a game from the database
+	if !t.Replay || !t.Visible || t.ExtraOptions.DatabaseID <= 0 {
+		s.Warning("Table " + strconv.FormatUint(t.ID, 10) + " is not a shared replay of a " +
+			"game from the database, so you cannot bookmark it.")
+		return
+	}
+
+	// Validate that this person is spectating the shared replay
+	if !t.IsActivelySpectating(s.UserID) {
+		s.Warning("You are not in shared replay " + strconv.FormatUint(t.ID, 10) + ".")
+		return
+	}
+
+	// Validate the comment
+	if !sanitizeReplayBookmarkComment(s, d) {
+		return
+	}
+
+	// Validate the turn
+	if valid, message := isReplayBookmarkTurnValid(t.ExtraOptions.DatabaseID, d.Segment); !valid {
+		s.Warning(message)
+		return
+	}
+
+	replayBookmark(s, d, t)
+}
+
+func replayBookmark(s *Session, d *CommandData, t *Table) {
+	if _, err := models.ReplayBookmarks.Insert(
+		t.ExtraOptions.DatabaseID,
+		s.UserID,
+		d.Segment,
+		d.Comment,
+	); err != nil {
+		logger.Error("Failed to insert a bookmark for game " +
+			strconv.Itoa(t.ExtraOptions.DatabaseID) + ": " + err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	}
+
+	t.NotifyReplayBookmarks()
+}
+
+// sanitizeReplayBookmarkComment cleans up the comment of a bookmark in the same way as a note
+// It returns false (after sending a warning) if the comment is not valid
+func sanitizeReplayBookmarkComment(s *Session, d *CommandData) bool {
+	// Validate the length
+	// (we do this first to prevent wasting CPU cycles on validating extremely long comments)
+	if len(d.Comment) > MaxReplayBookmarkCommentLength {
+		s.Warning("Bookmark comments cannot be longer than " +
+			strconv.Itoa(MaxReplayBookmarkCommentLength) + " characters.")
+		return false
+	}
+
+	// Remove any non-printable characters, if any
+	d.Comment = removeNonPrintableCharacters(d.Comment)
+
+	// Check for valid UTF8
+	if !utf8.Valid([]byte(d.Comment)) {
+		s.Warning("Bookmark comments must contain valid UTF8 characters.")
+		return false
+	}
+
+	// Replace any whitespace that is not a space with a space
+	msg2 := d.Comment
+	for _, letter := range msg2 {
+		if unicode.IsSpace(letter) && letter != ' ' {
+			d.Comment = strings.ReplaceAll(d.Comment, string(letter), " ")
+		}
+	}
+
+	// Trim whitespace from both sides
+	d.Comment = strings.TrimSpace(d.Comment)
+
+	if d.Comment == "" {
+		s.Warning("Bookmarks must have a comment.")
+		return false
+	}
+
+	// Validate that the comment does not contain an unreasonable amount of consecutive diacritics
+	// (accents)
+	if numConsecutiveDiacritics(d.Comment) > ConsecutiveDiacriticsAllowed {
+		s.Warning("Bookmark comments cannot contain more than " +
+			strconv.Itoa(ConsecutiveDiacriticsAllowed) + " consecutive diacritics.")
+		return false
+	}
+
+	return true
+}
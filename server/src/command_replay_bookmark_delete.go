@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+// commandReplayBookmarkDelete is sent when a user deletes one of their bookmarks in a shared
+// replay
+//
+// Example data:
+//
+//	{
+//	  tableID: 123,
+//	  bookmarkID: 456,
+//	}
+func commandReplayBookmarkDelete(ctx context.Context, s *Session, d *CommandData) {
+	t, exists := getTableAndLock(ctx, s, d.TableID, !d.NoTableLock, !d.NoTablesLock)
+	if !exists {
+		return
+	}
+	if !d.NoTableLock {
+		defer t.Unlock(ctx)
+	}
+
+	// Validate that this is a shared replay of a game from the database
+	if !t.Replay || !t.Visible || t.ExtraOptions.DatabaseID <= 0 {
+		s.Warning("Table " + strconv.FormatUint(t.ID, 10) + " is not a shared replay of a " +
+			"game from the database, so you cannot delete bookmarks in it.")
+		return
+	}
+
+	// Validate that this person is spectating the shared replay
+	if !t.IsActivelySpectating(s.UserID) {
+		s.Warning("You are not in shared replay " + strconv.FormatUint(t.ID, 10) + ".")
+		return
+	}
+
+	// Validate that the bookmark exists and that it belongs to this person
+	if !isReplayBookmarkOwner(s, d, t) {
+		return
+	}
+
+	replayBookmarkDelete(s, d, t)
+}
+
+func replayBookmarkDelete(s *Session, d *CommandData, t *Table) {
+	if _, err := models.ReplayBookmarks.Delete(d.BookmarkID, s.UserID); err != nil {
+		logger.Error("Failed to delete bookmark " + strconv.Itoa(d.BookmarkID) + ": " +
+			err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	}
+
+	t.NotifyReplayBookmarks()
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+// commandReplayBookmarkEdit is sent when a user changes the comment of one of their bookmarks in a
+// shared replay
+//
+// Example data:
+//
+//	{
+//	  tableID: 123,
+//	  bookmarkID: 456,
+//	  comment: 'This is where Bob should have played the red 2',
+//	}
+func commandReplayBookmarkEdit(ctx context.Context, s *Session, d *CommandData) {
+	t, exists := getTableAndLock(ctx, s, d.TableID, !d.NoTableLock, !d.NoTablesLock)
+	if !exists {
+		return
+	}
+	if !d.NoTableLock {
+		defer t.Unlock(ctx)
+	}
+
+	// Validate that this is a shared replay of a game from the database
+	if !t.Replay || !t.Visible || t.ExtraOptions.DatabaseID <= 0 {
+		s.Warning("Table " + strconv.FormatUint(t.ID, 10) + " is not a shared replay of a " +
+			"game from the database, so you cannot edit bookmarks in it.")
+		return
+	}
+
+	// Validate that this person is spectating the shared replay
+	if !t.IsActivelySpectating(s.UserID) {
+		s.Warning("You are not in shared replay " + strconv.FormatUint(t.ID, 10) + ".")
+		return
+	}
+
+	// Validate the comment
+	if !sanitizeReplayBookmarkComment(s, d) {
+		return
+	}
+
+	// Validate that the bookmark exists and that it belongs to this person
+	if !isReplayBookmarkOwner(s, d, t) {
+		return
+	}
+
+	replayBookmarkEdit(s, d, t)
+}
+
+func replayBookmarkEdit(s *Session, d *CommandData, t *Table) {
+	if _, err := models.ReplayBookmarks.Update(d.BookmarkID, s.UserID, d.Comment); err != nil {
+		logger.Error("Failed to update bookmark " + strconv.Itoa(d.BookmarkID) + ": " +
+			err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	}
+
+	t.NotifyReplayBookmarks()
+}
+
+// isReplayBookmarkOwner checks that a bookmark is from the game of the shared replay and that it
+// was created by the user (who is the only one allowed to edit or delete it)
+// It sends the user a warning if the check fails
+func isReplayBookmarkOwner(s *Session, d *CommandData, t *Table) bool {
+	var bookmark *ReplayBookmark
+	if exists, v, err := models.ReplayBookmarks.Get(d.BookmarkID); err != nil {
+		logger.Error("Failed to get bookmark " + strconv.Itoa(d.BookmarkID) + ": " + err.Error())
+		s.Error(DefaultErrorMsg)
+		return false
+	} else if !exists || v.GameID != t.ExtraOptions.DatabaseID {
+		s.Warning("Bookmark " + strconv.Itoa(d.BookmarkID) + " does not exist in this replay.")
+		return false
+	} else {
+		bookmark = v
+	}
+
+	if bookmark.UserID != s.UserID {
+		s.Warning("You can only change your own bookmarks.")
+		return false
+	}
+
+	return true
+}
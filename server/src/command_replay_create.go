@@ -186,6 +186,27 @@ func replayCreate(ctx context.Context, s *Session, d *CommandData) {
 			g.DatetimeStarted = v1
 			g.DatetimeFinished = v2
 		}
+
+		// Show the chat from the original game (including the pregame chat)
+		if v, err := models.ChatLog.GetByGame(t.ExtraOptions.DatabaseID); err != nil {
+			logger.Error("Failed to get the chat for game " +
+				"\"" + strconv.Itoa(t.ExtraOptions.DatabaseID) + "\": " + err.Error())
+			// Do not return on failed chat retrieval, since the replay still works without it
+		} else {
+			for _, dbChatMessage := range v {
+				username := dbChatMessage.Name
+				if dbChatMessage.DiscordName.Valid {
+					username = dbChatMessage.DiscordName.String
+				}
+				t.Chat = append(t.Chat, &TableChatMessage{
+					UserID:   0,
+					Username: username,
+					Msg:      dbChatMessage.Message,
+					Datetime: dbChatMessage.Datetime,
+					Server:   dbChatMessage.Name == "__server",
+				})
+			}
+		}
 	}
 
 	// Join the user to the new replay
@@ -258,7 +279,12 @@ func isJSONValid(d *CommandData) (bool, string) {
 					strconv.Itoa(action.Target) + "."
 				return false, msg
 			}
-			if action.Value != 0 {
+			// The value of a discard marks whether it was caused by running out of time
+			// (which can only happen in games with a turn time limit)
+			timeoutAllowed := action.Type == ActionTypeDiscard &&
+				d.GameJSON.Options.TurnTimeLimit != nil &&
+				*d.GameJSON.Options.TurnTimeLimit
+			if action.Value != 0 && !(timeoutAllowed && action.Value == DiscardValueTimeout) {
 				msg := "Action at index " + strconv.Itoa(i) +
 					" is a play or discard with a value of " + strconv.Itoa(action.Value) +
 					", which is nonsensical."
@@ -427,19 +453,31 @@ func loadDatabaseOptionsToTable(s *Session, databaseID int, t *Table) ([]*DBPlay
 		actions = v
 	}
 
+	// The deck is usually not stored in the database;
+	// the ordering of the cards is determined by using the game's seed
+	// Games that were imported from JSON have their deck stored, so we treat them like a JSON replay
+	var deck []*CardIdentity
+	if v, err := models.GameDecks.Get(databaseID); err != nil {
+		logger.Error("Failed to get the deck from the database for game " +
+			strconv.Itoa(databaseID) + ": " + err.Error())
+		s.Error(InitGameFail)
+		return nil, false
+	} else if len(v) > 0 {
+		deck = v
+		seed = ""
+	}
+
 	t.ExtraOptions = &ExtraOptions{
 		DatabaseID: databaseID,
 
 		NoWriteToDatabase: true,
-		JSONReplay:        false,
+		JSONReplay:        deck != nil,
 
 		CustomNumPlayers:           len(dbPlayers),
 		CustomCharacterAssignments: characterAssignments,
 		CustomSeed:                 seed,
-		// Setting "CustomDeck" is not necessary because the deck is not stored in the database;
-		// the ordering of the cards is determined by using the game's seed
-		CustomDeck:    nil,
-		CustomActions: actions,
+		CustomDeck:                 deck,
+		CustomActions:              actions,
 
 		Restarted:     false,
 		SetSeedSuffix: "",
@@ -456,9 +494,8 @@ func getCharacterAssignmentsFromDBPlayers(dbPlayers []*DBPlayer) []*CharacterAss
 		characterAssignments = append(characterAssignments, &CharacterAssignment{
 			// Characters are stored in the database as integers,
 			// so we convert it to the character name by using the character ID map
-			Name: characterIDMap[dbPlayer.CharacterAssignment],
-			// Character metadata is stored in the database as value + 1
-			Metadata: dbPlayer.CharacterMetadata - 1,
+			Name:     characterIDMap[dbPlayer.CharacterAssignment],
+			Metadata: dbPlayer.CharacterMetadata,
 		})
 	}
 
@@ -492,6 +529,10 @@ func loadJSONOptionsToTable(d *CommandData, t *Table) {
 	if d.GameJSON.Options.Speedrun != nil {
 		speedrun = *d.GameJSON.Options.Speedrun
 	}
+	turnTimeLimit := false
+	if d.GameJSON.Options.TurnTimeLimit != nil {
+		turnTimeLimit = *d.GameJSON.Options.TurnTimeLimit
+	}
 	cardCycle := false
 	if d.GameJSON.Options.CardCycle != nil {
 		cardCycle = *d.GameJSON.Options.CardCycle
@@ -532,6 +573,7 @@ func loadJSONOptionsToTable(d *CommandData, t *Table) {
 		TimeBase:              timeBase,
 		TimePerTurn:           timePerTurn,
 		Speedrun:              speedrun,
+		TurnTimeLimit:         turnTimeLimit,
 		CardCycle:             cardCycle,
 		DeckPlays:             deckPlays,
 		EmptyClues:            emptyClues,
@@ -575,14 +617,17 @@ func loadFakePlayers(t *Table, playerNames []string) {
 		id := (i + 1) * -1
 
 		player := &Player{
-			UserID:     id,
-			Name:       name,
-			Session:    NewFakeSession(id, name),
-			Present:    true,
-			Stats:      &PregameStats{},
-			Typing:     false,
-			LastTyped:  time.Time{},
-			VoteToKill: false,
+			UserID:       id,
+			Name:         name,
+			Session:      NewFakeSession(id, name),
+			Present:      true,
+			Stats:        &PregameStats{},
+			Typing:       false,
+			LastTyped:    time.Time{},
+			VoteToKill:   false,
+			VoteToResign: false,
+
+			DatetimeDisconnected: time.Time{},
 		}
 		t.Players = append(t.Players, player)
 	}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+// commandReplayImport is sent when the user submits a JSON deck definition (e.g. a puzzle)
+// that they want to be saved to the database as a normal game
+// In order to derive the score and the end condition,
+// we need to play through a mock game using the actions
+// Every player in the JSON must be an existing user; they are seated in the order given
+// The seed in the JSON is ignored, since the deck is always stored explicitly
+//
+// Example data:
+// {
+//   gameJSON: '{"players"=[],"deck"=[],"actions"=[]}',
+// }
+func commandReplayImport(ctx context.Context, s *Session, d *CommandData) {
+	if d.GameJSON == nil {
+		s.Warning("You must send the game specification in the \"gameJSON\" field.")
+		return
+	}
+	d.GameJSON.Seed = ""
+	if valid, message := isJSONValid(d); !valid {
+		s.Warning(message)
+		return
+	}
+	variant := variants[*d.GameJSON.Options.Variant]
+	if valid, message := isJSONDeckValid(variant, d.GameJSON.Deck); !valid {
+		s.Warning(message)
+		return
+	}
+
+	// Validate that all of the players exist
+	// (the session of a fake player is not associated with a user in the database)
+	userIDs := make([]int, 0)
+	for _, name := range d.GameJSON.Players {
+		if exists, user, err := models.Users.Get(name); err != nil {
+			logger.Error("Failed to get user \"" + name + "\": " + err.Error())
+			s.Error(DefaultErrorMsg)
+			return
+		} else if !exists {
+			s.Warning("The player of \"" + name + "\" does not exist in the database.")
+			return
+		} else if intInSlice(user.ID, userIDs) {
+			s.Warning("The player of \"" + name + "\" is specified more than once.")
+			return
+		} else {
+			userIDs = append(userIDs, user.ID)
+		}
+	}
+
+	var gameRow GameRow
+	if v, success := replayImportEmulate(ctx, s, d); !success {
+		return
+	} else {
+		gameRow = v
+	}
+
+	def := GameJSONDefinition{
+		GameRow:  gameRow,
+		GameJSON: d.GameJSON,
+		UserIDs:  userIDs,
+	}
+	var databaseID int
+	if v, err := models.Games.InsertFromJSON(def); err != nil {
+		logger.Error("Failed to import a JSON game for user \"" + s.Username + "\": " + err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	} else {
+		databaseID = v
+	}
+
+	logger.Info("User \"" + s.Username + "\" imported a JSON game to database ID " +
+		strconv.Itoa(databaseID) + ".")
+	msg := "The JSON game was imported as game #" + strconv.Itoa(databaseID) + "."
+	chatServerSendPM(s, msg, "lobby")
+}
+
+// replayImportEmulate plays through the JSON game with fake players and returns the results
+func replayImportEmulate(ctx context.Context, s *Session, d *CommandData) (GameRow, bool) {
+	tables.Lock(ctx)
+	defer tables.Unlock(ctx)
+
+	t := NewTable("Imported JSON game", -1)
+	t.Lock(ctx)
+	defer t.Unlock(ctx)
+	t.Visible = false
+
+	loadJSONOptionsToTable(d, t)
+	loadFakePlayers(t, d.GameJSON.Players)
+	tables.Set(t.ID, t)
+	defer deleteTable(t)
+
+	commandTableStart(ctx, t.Players[0].Session, &CommandData{ // nolint: exhaustivestruct
+		TableID:      t.ID,
+		NoTableLock:  true,
+		NoTablesLock: true,
+	})
+	g := t.Game
+	if g == nil {
+		logger.Error("Failed to start the game when importing a JSON game.")
+		s.Error(InitGameFail)
+		return GameRow{}, false // nolint: exhaustivestruct
+	}
+
+	if g.InvalidActionOccurred {
+		s.Warning("One of the actions in the JSON was not valid.")
+		return GameRow{}, false // nolint: exhaustivestruct
+	}
+	if g.EndCondition == EndConditionInProgress {
+		s.Warning("The actions in the JSON must play the game through to the end.")
+		return GameRow{}, false // nolint: exhaustivestruct
+	}
+
+	return GameRow{
+		Name:             t.Name,
+		Options:          t.Options,
+		Seed:             "", // The seed is derived from the deck when the game is inserted
+		Score:            g.Score,
+		NumTurns:         g.Turn,
+		NumStrikes:       g.Strikes,
+		EndCondition:     g.EndCondition,
+		DatetimeStarted:  g.DatetimeStarted,
+		DatetimeFinished: g.DatetimeFinished,
+	}, true
+}
@@ -21,6 +21,9 @@ var (
 	tableNameHasValidCharacters = regexp.MustCompile(`^[a-zA-Z0-9 !@#$\(\)\-_=\+;:,\.\?]+$`).MatchString
 	// Only allow [:alphanum], [-]
 	seedHasValidCharacters = regexp.MustCompile(`^[\-a-zA-Z0-9]+$`).MatchString
+	// The server generates seed suffixes of 0, 1, 2, and so on,
+	// so user-chosen seed names that are only digits would collide with them
+	seedIsGenerated = regexp.MustCompile(`^[0-9]+$`).MatchString
 )
 
 // Data relating to games created with a special custom prefix (e.g. "!seed")
@@ -95,11 +98,39 @@ func commandTableCreate(ctx context.Context, s *Session, d *CommandData) {
 		}
 	}
 
+	if d.Visibility != "" && d.Visibility != "friends" {
+		s.Warning("That is not a valid table visibility.")
+		return
+	}
+
 	// Validate that the maximum player count is valid, default to 5
 	if d.MaxPlayers < 2 || d.MaxPlayers > 6 {
 		d.MaxPlayers = 5
 	}
 
+	if valid, message := isCharacterPoolValid(d.Options, d.MaxPlayers); !valid {
+		s.Warning(message)
+		return
+	}
+
+	// Validate that the user is not creating too many tables
+	if !strings.HasPrefix(s.Username, "Bot-") && !d.BypassRateLimit {
+		if count, err := models.RateLimits.CountRecent(
+			s.UserID,
+			RateLimitActionTableCreate,
+			TableCreateRateLimitWindow,
+		); err != nil {
+			logger.Error("Failed to count the recent tables created by user \"" + s.Username +
+				"\": " + err.Error())
+			s.Error(CreateGameFail)
+			return
+		} else if count >= TableCreateRateLimit {
+			s.Warning("You have created too many tables recently. " +
+				"Please wait a few minutes before creating another one.")
+			return
+		}
+	}
+
 	tableCreate(ctx, s, d, data)
 }
 
@@ -140,6 +171,7 @@ func tableCreate(ctx context.Context, s *Session, d *CommandData, data *SpecialG
 	t.Lock(ctx)
 	defer t.Unlock(ctx)
 	t.Visible = !d.HidePregame
+	t.FriendsOnly = d.Visibility == "friends"
 	t.PasswordHash = passwordHash
 	t.MaxPlayers = d.MaxPlayers
 	t.Options = d.Options
@@ -198,6 +230,13 @@ func tableCreate(ctx context.Context, s *Session, d *CommandData, data *SpecialG
 	// Add the table to a map so that we can keep track of all of the active tables
 	tables.Set(t.ID, t)
 
+	if !d.BypassRateLimit {
+		if err := models.RateLimits.Record(s.UserID, RateLimitActionTableCreate); err != nil {
+			logger.Error("Failed to record the table creation for user \"" + s.Username + "\": " +
+				err.Error())
+		}
+	}
+
 	logger.Info(t.GetName() + "User \"" + s.Username + "\" created a table.")
 	// (a "table" message will be sent in the "commandTableJoin" function below)
 
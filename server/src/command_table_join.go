@@ -64,6 +64,13 @@ func commandTableJoin(ctx context.Context, s *Session, d *CommandData) {
 		}
 	}
 
+	// Validate that the owner has friended them, if needed
+	// (players that are being moved from a restarted game are exempt, like with the password)
+	if !d.BypassPassword && !t.IsOpenTo(s) {
+		s.Warning("That table is only open to friends of the owner.")
+		return
+	}
+
 	// Validate that they have not been previously kicked from this game
 	if _, ok := t.KickedPlayers[s.UserID]; ok {
 		s.Warning("You cannot join a game that you have been kicked from.")
@@ -129,9 +136,12 @@ func tableJoin(ctx context.Context, s *Session, d *CommandData, t *Table) {
 			NumGames: numGames,
 			Variant:  variantStats,
 		},
-		Typing:     false,
-		LastTyped:  time.Time{},
-		VoteToKill: false,
+		Typing:       false,
+		LastTyped:    time.Time{},
+		VoteToKill:   false,
+		VoteToResign: false,
+
+		DatetimeDisconnected: time.Time{},
 	}
 
 	t.Players = append(t.Players, p)
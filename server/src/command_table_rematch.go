@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+var (
+	// e.g. "p2v0s1" matches "1"
+	seedSuffixRegExp = regexp.MustCompile(`^p\d+v\d+s(.+)$`)
+)
+
+// commandTableRematch is sent when the user wants to start a new game with the same options as a
+// game that has already finished
+// Unlike "tableRestart", the players do not have to be in a shared replay together;
+// the other players from the original game are sent an invitation instead
+//
+// Example data:
+//
+//	{
+//	  databaseID: 12345,
+//	  // Optional; a new deck will be used unless this is true
+//	  sameSeed: false,
+//	}
+func commandTableRematch(ctx context.Context, s *Session, d *CommandData) {
+	// Validate that the game exists
+	if exists, err := models.Games.Exists(d.DatabaseID); err != nil {
+		logger.Error("Failed to check to see if game " + strconv.Itoa(d.DatabaseID) +
+			" exists: " + err.Error())
+		s.Error(CreateGameFail)
+		return
+	} else if !exists {
+		s.Warning("Game #" + strconv.Itoa(d.DatabaseID) + " does not exist in the database.")
+		return
+	}
+
+	// Validate that this person was one of the players in the game
+	var dbPlayers []*DBPlayer
+	if v, err := models.Games.GetPlayers(d.DatabaseID); err != nil {
+		logger.Error("Failed to get the players from the database for game " +
+			strconv.Itoa(d.DatabaseID) + ": " + err.Error())
+		s.Error(CreateGameFail)
+		return
+	} else {
+		dbPlayers = v
+	}
+	playedInOriginalGame := false
+	for _, dbPlayer := range dbPlayers {
+		if dbPlayer.ID == s.UserID {
+			playedInOriginalGame = true
+			break
+		}
+	}
+	if !playedInOriginalGame {
+		s.Warning("You cannot start a rematch of a game unless you played in it.")
+		return
+	}
+
+	var options *Options
+	if v, err := models.Games.GetStartOptions(d.DatabaseID); err != nil {
+		logger.Error("Failed to get the options from the database for game " +
+			strconv.Itoa(d.DatabaseID) + ": " + err.Error())
+		s.Error(CreateGameFail)
+		return
+	} else {
+		options = v
+	}
+
+	newTableName := ""
+	if d.SameSeed {
+		// Re-use the deck from the original game by creating a "!seed" table
+		// (the seed prefix will be the same, since the player count and variant are the same)
+		var seed string
+		if v, err := models.Games.GetSeed(d.DatabaseID); err != nil {
+			logger.Error("Failed to get the seed from the database for game " +
+				strconv.Itoa(d.DatabaseID) + ": " + err.Error())
+			s.Error(CreateGameFail)
+			return
+		} else {
+			seed = v
+		}
+		match := seedSuffixRegExp.FindAllStringSubmatch(seed, -1)
+		if len(match) == 0 || !seedHasValidCharacters(match[0][1]) {
+			s.Warning("Game #" + strconv.Itoa(d.DatabaseID) + " does not have a seed that " +
+				"can be played again.")
+			return
+		}
+		newTableName = "!seed " + match[0][1]
+	} else if options.TableName == "" || strings.HasPrefix(options.TableName, "!") {
+		// Games with a custom prefix (e.g. "!seed" or "!replay") should not be recreated with it
+		newTableName = getName()
+	} else {
+		newTableName = getRestartedTableName(options.TableName)
+	}
+
+	// Validate that the server is not about to go offline
+	if checkImminentShutdown(s) {
+		return
+	}
+
+	// Validate that the server is not undergoing maintenance
+	if maintenanceMode.IsSet() {
+		s.Warning("The server is undergoing maintenance. " +
+			"You cannot start any new games for the time being.")
+		return
+	}
+
+	tableRematch(ctx, s, d, newTableName, options, dbPlayers)
+}
+
+func tableRematch(
+	ctx context.Context,
+	s *Session,
+	d *CommandData,
+	newTableName string,
+	options *Options,
+	dbPlayers []*DBPlayer,
+) {
+	// Since this is a function that changes a user's relationship to tables,
+	// we must acquires the tables lock to prevent race conditions
+	if !d.NoTablesLock {
+		tables.Lock(ctx)
+		defer tables.Unlock(ctx)
+	}
+
+	// Validate that the player is not joined to another table
+	// (this has to be checked before the table is created, since afterwards we would not be able to
+	// tell the new table apart from the old one)
+	if len(tables.GetTablesUserPlaying(s.UserID)) > 0 {
+		s.Warning("You cannot join more than one table at a time. " +
+			"Terminate your other game before starting a rematch.")
+		return
+	}
+
+	commandTableCreate(ctx, s, &CommandData{ // nolint: exhaustivestruct
+		Name:         newTableName,
+		Options:      options,
+		MaxPlayers:   options.MaxPlayers,
+		NoTablesLock: true,
+
+		AllowGeneratedSeed: d.SameSeed,
+	})
+
+	// The new table is the only one that the creator can be playing at, since they were not playing
+	// at any tables before it was created
+	// (if the table failed to be created, they will have already been sent a warning)
+	tableIDs := tables.GetTablesUserPlaying(s.UserID)
+	if len(tableIDs) != 1 {
+		return
+	}
+	var t *Table
+	if v, ok := tables.Get(tableIDs[0], false); !ok {
+		return
+	} else {
+		t = v
+	}
+
+	// Invite the rest of the players from the original game
+	// Players who are offline or have since started a new game are skipped
+	msg := s.Username + " has started a rematch of game #" + strconv.Itoa(d.DatabaseID) +
+		" at table: " + t.Name
+	for _, dbPlayer := range dbPlayers {
+		if dbPlayer.ID == s.UserID {
+			continue
+		}
+
+		s2, ok := sessions.Get(dbPlayer.ID)
+		if !ok || s2 == nil {
+			continue
+		}
+
+		if len(tables.GetTablesUserPlaying(s2.UserID)) > 0 {
+			continue
+		}
+
+		chatServerSendPM(s2, msg, "lobby")
+	}
+}
@@ -188,22 +188,7 @@ func tableRestart(
 		// there will not be an initial name for the table
 		newTableName = getName()
 	} else {
-		// Generate a new name for the game based on how many times the players have restarted
-		// e.g. "logic only" --> "logic only (#2)" --> "logic only (#3)"
-		oldTableName := t.InitialName
-		gameNumber := 2 // By default, this is the second game of a particular table
-		match := roomNameRegExp.FindAllStringSubmatch(oldTableName, -1)
-		if len(match) != 0 {
-			oldTableName = match[0][1] // This is the name of the room without the "(#2)" part
-			gameNumber, _ = strconv.Atoi(match[0][2])
-			gameNumber++
-		}
-		tableNameSuffix := " (#" + strconv.Itoa(gameNumber) + ")"
-		maxGameNameLengthWithoutSuffix := MaxGameNameLength - len(tableNameSuffix)
-		if len(oldTableName) > maxGameNameLengthWithoutSuffix {
-			oldTableName = oldTableName[0 : maxGameNameLengthWithoutSuffix-1]
-		}
-		newTableName = oldTableName + tableNameSuffix
+		newTableName = getRestartedTableName(t.InitialName)
 	}
 
 	// If passwordHash was nonempty, preserve old value
@@ -212,6 +197,11 @@ func tableRestart(
 		passwordHash = t.PasswordHash
 	}
 
+	visibility := ""
+	if t.FriendsOnly {
+		visibility = "friends"
+	}
+
 	// The shared replay should now be deleted, since all of the players have left
 	// Now, create the new game but hide it from the lobby
 	commandTableCreate(ctx, s, &CommandData{ // nolint: exhaustivestruct
@@ -224,6 +214,9 @@ func tableRestart(
 		MaxPlayers:     t.MaxPlayers,
 		PasswordHash:   passwordHash,
 		BypassPassword: true,
+		Visibility:     visibility,
+
+		BypassRateLimit: true,
 	})
 
 	// Find the table ID for the new game
@@ -316,3 +309,22 @@ func tableRestart(
 		}
 	}
 }
+
+// getRestartedTableName generates a new name for a game based on how many times the players have
+// restarted
+// e.g. "logic only" --> "logic only (#2)" --> "logic only (#3)"
+func getRestartedTableName(oldTableName string) string {
+	gameNumber := 2 // By default, this is the second game of a particular table
+	match := roomNameRegExp.FindAllStringSubmatch(oldTableName, -1)
+	if len(match) != 0 {
+		oldTableName = match[0][1] // This is the name of the room without the "(#2)" part
+		gameNumber, _ = strconv.Atoi(match[0][2])
+		gameNumber++
+	}
+	tableNameSuffix := " (#" + strconv.Itoa(gameNumber) + ")"
+	maxGameNameLengthWithoutSuffix := MaxGameNameLength - len(tableNameSuffix)
+	if len(oldTableName) > maxGameNameLengthWithoutSuffix {
+		oldTableName = oldTableName[0 : maxGameNameLengthWithoutSuffix-1]
+	}
+	return oldTableName + tableNameSuffix
+}
@@ -68,6 +68,12 @@ func commandTableStart(ctx context.Context, s *Session, d *CommandData) {
 		}
 	}
 
+	// Validate that there are enough allowed characters for everyone
+	if valid, message := isCharacterPoolValid(t.Options, len(t.Players)); !valid {
+		s.Warning(message)
+		return
+	}
+
 	if d.IntendedPlayers != nil {
 		// Check that the game is starting with the intended set of players
 
@@ -121,9 +127,7 @@ func tableStart(ctx context.Context, s *Session, d *CommandData, t *Table) {
 	// Handle setting the seed
 	shuffleDeck := true
 	shufflePlayers := true
-	seedPrefix := "p" + strconv.Itoa(len(t.Players)) +
-		"v" + strconv.Itoa(variant.ID) +
-		"s" // e.g. "p2v0s" for a 2-player no variant game
+	seedPrefix := getSeedPrefix(len(t.Players), variant.ID)
 	if t.ExtraOptions.JSONReplay {
 		// This is a replay from arbitrary JSON data (or a custom game from arbitrary JSON data)
 		shufflePlayers = false
@@ -158,7 +162,6 @@ func tableStart(ctx context.Context, s *Session, d *CommandData, t *Table) {
 	logger.Info("Shuffling deck: " + strconv.FormatBool(shuffleDeck))
 	logger.Info("Shuffling players: " + strconv.FormatBool(shufflePlayers))
 
-	setSeed(g.Seed) // Seed the random number generator
 	if shuffleDeck {
 		g.ShuffleDeck()
 	}
@@ -300,6 +303,12 @@ func emulateActions(ctx context.Context, s *Session, d *CommandData, t *Table) {
 			Value:        action.Value,
 			NoTableLock:  true,
 			NoTablesLock: d.NoTablesLock,
+
+			// The actions were already validated when they were recorded (or imported)
+			TurnTimeLimitExpired: action.Type == ActionTypeDiscard &&
+				action.Value == DiscardValueTimeout,
+			EndedByServer: action.Type == ActionTypeEndGame ||
+				action.Type == ActionTypeEndGameByVote,
 		})
 
 		if g.InvalidActionOccurred {
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"strconv"
+	"time"
 
 	"github.com/Hanabi-Live/hanabi-live/logger"
 )
@@ -68,7 +69,17 @@ func tableUnattendPlayer(ctx context.Context, s *Session, d *CommandData, t *Tab
 	p.Present = false
 
 	if t.Running {
+		// Only the latest disconnection is tracked
+		p.DatetimeDisconnected = time.Now()
 		t.NotifyConnected()
+
+		// The clock stops in a game with a turn time limit if it was their turn
+		g := t.Game
+		if g.StartedTimer && i == g.ActivePlayerIndex && t.Options.TurnTimeLimit &&
+			!t.ExtraOptions.NoWriteToDatabase {
+
+			g.PauseTurnTimeLimit(ctx)
+		}
 	} else {
 		t.NotifyPlayerChange()
 	}
@@ -35,6 +35,11 @@ func commandTableUpdate(ctx context.Context, s *Session, d *CommandData) {
 		return
 	}
 
+	if d.Visibility != "" && d.Visibility != "friends" {
+		s.Warning("That is not a valid table visibility.")
+		return
+	}
+
 	if s.UserID != t.OwnerID {
 		// Non game host sends new options
 		// They are sent to the table chat as a proposal
@@ -91,6 +96,9 @@ func commandTableUpdate(ctx context.Context, s *Session, d *CommandData) {
 		if newOptions.Speedrun != tableOptions.Speedrun {
 			options += span + "Speedrun: <b>" + yesNoFromBoolean(newOptions.Speedrun) + endSpan
 		}
+		if newOptions.TurnTimeLimit != tableOptions.TurnTimeLimit {
+			options += span + "Turn Time Limit: <b>" + yesNoFromBoolean(newOptions.TurnTimeLimit) + endSpan
+		}
 		if newOptions.CardCycle != tableOptions.CardCycle {
 			options += span + "Card Cycling: <b>" + yesNoFromBoolean(newOptions.CardCycle) + endSpan
 		}
@@ -112,6 +120,9 @@ func commandTableUpdate(ctx context.Context, s *Session, d *CommandData) {
 		if newOptions.DetrimentalCharacters != tableOptions.DetrimentalCharacters {
 			options += span + "Detrimental Characters: <b>" + yesNoFromBoolean(newOptions.DetrimentalCharacters) + endSpan
 		}
+		if !intSlicesEqual(newOptions.AllowedCharacters, tableOptions.AllowedCharacters) {
+			options += span + "Allowed Characters: <b>" + getCharacterPoolDescription(newOptions) + endSpan
+		}
 
 		if options == "" {
 			// nothing is changed
@@ -184,6 +195,12 @@ func commandTableUpdate(ctx context.Context, s *Session, d *CommandData) {
 
 	// Sanitize max players
 	d.MaxPlayers = between(d.MaxPlayers, 2, 6, 5)
+
+	if valid, message := isCharacterPoolValid(d.Options, d.MaxPlayers); !valid {
+		s.Warning(message)
+		return
+	}
+
 	// Kick extra players
 	if d.MaxPlayers < len(t.Players) {
 		extraPlayers := t.Players[d.MaxPlayers:]
@@ -216,10 +233,12 @@ func commandTableUpdate(ctx context.Context, s *Session, d *CommandData) {
 func tableUpdate(ctx context.Context, s *Session, d *CommandData, data *SpecialGameData, t *Table) {
 	// Local variables
 	variant := variants[d.Options.VariantName]
+	wasFriendsOnly := t.FriendsOnly
 
 	// First, change the table options
 	t.Name = d.Name
 	t.Visible = !d.HidePregame
+	t.FriendsOnly = d.Visibility == "friends"
 	t.MaxPlayers = d.MaxPlayers
 	t.Options = d.Options
 	t.ExtraOptions = &ExtraOptions{
@@ -262,6 +281,12 @@ func tableUpdate(ctx context.Context, s *Session, d *CommandData, data *SpecialG
 	// Update the variant in the table list for everyone in the lobby
 	notifyAllTable(t)
 
+	// If the table just became friends-only, it must be removed from the table list of everyone who
+	// can no longer see it
+	if t.FriendsOnly && !wasFriendsOnly {
+		notifyAllTableGoneInvisible(t)
+	}
+
 	msg := s.Username + " has changed game options."
 	chatServerSend(ctx, msg, t.GetRoomName(), d.NoTablesLock)
 
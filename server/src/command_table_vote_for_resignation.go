@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+// commandTableVoteForResignation is sent when a player wants their team to resign the game
+// (e.g. when the game is hopeless)
+// Unlike a termination, a resigned game is not counted as a loss in the stats
+//
+// Example data:
+//
+//	{
+//	  tableID: 5,
+//	}
+func commandTableVoteForResignation(ctx context.Context, s *Session, d *CommandData) {
+	t, exists := getTableAndLock(ctx, s, d.TableID, !d.NoTableLock, !d.NoTablesLock)
+	if !exists {
+		return
+	}
+	if !d.NoTableLock {
+		defer t.Unlock(ctx)
+	}
+
+	// Validate that they are in the game
+	playerIndex := t.GetPlayerIndexFromID(s.UserID)
+	if playerIndex == -1 {
+		s.Warning("You are not playing at table " + strconv.FormatUint(t.ID, 10) + ", " +
+			"so you cannot vote to resign it.")
+		return
+	}
+
+	// Validate that the game has started
+	if !t.Running {
+		s.Warning("You can not vote to resign a game that has not started yet.")
+		return
+	}
+
+	// Validate that it is not a replay
+	if t.Replay {
+		s.Warning("You can not vote to resign a replay.")
+		return
+	}
+
+	voteForResignation(ctx, s, d, t, playerIndex)
+}
+
+func voteForResignation(ctx context.Context, s *Session, d *CommandData, t *Table, playerIndex int) {
+	newVote := t.ChangeResignVote(playerIndex)
+
+	// The other players need to know about the vote so that they can agree to it
+	msg := s.Username + " "
+	if newVote {
+		msg += "voted to resign the game."
+	} else {
+		msg += "took back their vote to resign the game."
+	}
+	chatServerSend(ctx, msg, t.GetRoomName(), d.NoTablesLock)
+
+	if t.ShouldResignByVotes() {
+		// Record the resignation against every player who voted for it
+		// (this is decided by the players themselves, so there is no moderator ID)
+		reason := "Table #" + strconv.FormatUint(t.ID, 10) + " (" + t.Name + ")"
+		for _, i := range t.GetResignVotes() {
+			if err := models.ModLog.Insert(
+				0,
+				t.Players[i].UserID,
+				ModLogActionResign,
+				reason,
+			); err != nil {
+				logger.Error("Failed to insert the moderator log entry for the resignation: " +
+					err.Error())
+			}
+		}
+
+		commandAction(ctx, s, &CommandData{ // nolint: exhaustivestruct
+			TableID:     t.ID,
+			Type:        ActionTypeEndGameByVote,
+			Target:      -1,
+			Value:       EndConditionResigned,
+			Votes:       t.GetResignVotes(),
+			NoTableLock: true,
+
+			EndedByServer: true,
+		})
+	}
+}
@@ -90,8 +90,9 @@ func tag(ctx context.Context, s *Session, d *CommandData, t *Table) {
 		return
 	}
 
-	msg := s.Username + " has added a game tag of \"" + d.Msg + "\"."
-	chatServerSend(ctx, msg, t.GetRoomName(), d.NoTablesLock)
+	// Tags are per-user, so the acknowledgement is only sent to them
+	msg := "Successfully added a tag of \"" + d.Msg + "\"."
+	chatServerSendPM(s, msg, d.Room)
 }
 
 func sanitizeTag(tag string) (string, string) {
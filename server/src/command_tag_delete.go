@@ -59,9 +59,9 @@ func tagDelete(ctx context.Context, s *Session, d *CommandData, t *Table) {
 		return
 	}
 
-	// Get the existing tags from the database
+	// Get the existing tags of that user from the database
 	var tags []string
-	if v, err := models.GameTags.GetAll(t.ExtraOptions.DatabaseID); err != nil {
+	if v, err := models.GameTags.GetAllByUserID(t.ExtraOptions.DatabaseID, s.UserID); err != nil {
 		logger.Error("Failed to get the tags for game ID " +
 			strconv.Itoa(t.ExtraOptions.DatabaseID) + ": " + err.Error())
 		s.Error(DefaultErrorMsg)
@@ -77,13 +77,14 @@ func tagDelete(ctx context.Context, s *Session, d *CommandData, t *Table) {
 	}
 
 	// Delete it from the database
-	if err := models.GameTags.Delete(t.ExtraOptions.DatabaseID, d.Msg); err != nil {
+	if err := models.GameTags.Delete(t.ExtraOptions.DatabaseID, s.UserID, d.Msg); err != nil {
 		logger.Error("Failed to delete a tag for game ID " +
 			strconv.Itoa(t.ExtraOptions.DatabaseID) + ": " + err.Error())
 		s.Error(DefaultErrorMsg)
 		return
 	}
 
-	msg := s.Username + " has deleted a game tag of \"" + d.Msg + "\"."
-	chatServerSend(ctx, msg, t.GetRoomName(), d.NoTablesLock)
+	// Tags are per-user, so the acknowledgement is only sent to them
+	msg := "Successfully deleted the tag of \"" + d.Msg + "\"."
+	chatServerSendPM(s, msg, d.Room)
 }
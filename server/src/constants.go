@@ -29,6 +29,14 @@ const (
 	ActionTypeEndGameByVote
 )
 
+// The value of a discard action is normally unused
+// The server uses this value to mark a discard that was performed automatically because the player
+// ran out of time in a game with a turn time limit
+const (
+	DiscardValueNormal = iota
+	DiscardValueTimeout
+)
+
 const (
 	ClueTypeColor = iota
 	ClueTypeRank
@@ -48,6 +56,8 @@ const (
 	EndConditionAllOrNothingFail     = 8
 	EndConditionAllOrNothingSoftlock = 9
 	EndConditionTerminatedByVote     = 10
+	EndConditionAbandoned            = 11
+	EndConditionResigned             = 12
 )
 
 // When in a shared replay, spectators can send certain types of "actions" to the server to
@@ -109,6 +119,36 @@ const (
 	// The amount of time that a game is inactive before it is killed by the server
 	IdleGameTimeout = time.Minute * 30
 
+	// The amount of time that every player in an ongoing game can be disconnected before the game
+	// is considered to be abandoned (this can be overridden with "ABANDONED_GAME_TIMEOUT")
+	DefaultAbandonedGameTimeout = time.Minute * 10
+	AbandonedGameSweepInterval  = time.Minute
+
+	// Ongoing games are periodically saved to the database so that they can be restored if the
+	// server crashes
+	CheckpointInterval = time.Second * 30
+
+	// Users can only create a certain number of tables within a rolling window
+	// (bots are exempt)
+	TableCreateRateLimit       = 10
+	TableCreateRateLimitWindow = time.Minute * 10
+
+	// Old entries in the "rate_limits" table are periodically pruned
+	// (this must be longer than every rate limit window)
+	RateLimitPruneInterval = time.Hour
+	RateLimitPruneAge      = time.Hour * 24
+
+	// In speedruns with a turn time limit, the active player must perform an action within this
+	// amount of time or their newest card will be automatically discarded
+	// The clock stops while the active player is disconnected, up to the grace period
+	TurnTimeLimit            = time.Second * 10
+	TurnTimeLimitGracePeriod = time.Second * 30
+
+	// Players start at this rating for every variant and number of players,
+	// and a single game can change it by at most this many points (in the Elo fashion)
+	DefaultRating = 1500
+	RatingKFactor = 32
+
 	// We want to validate string inputs for too many consecutive diacritics
 	// This prevents the attack where messages can have a lot of diacritics and cause overflow
 	// into sections above and below the text
@@ -73,6 +73,22 @@ type Game struct {
 	Paused           bool
 	PausePlayerIndex int
 	PauseCount       int
+	DatetimePaused   time.Time // Zero if the game is not paused
+	// The time that the active player spent on their turn before the game was paused
+	// (so that pauses do not count towards the time of the action)
+	TurnTimeBeforePause time.Duration
+	// The time that the active player spent on their turn before the game was serialized
+	// (so that it can still be charged to them after the game is restored)
+	TurnTimeBeforeSave time.Duration
+	// The time that was spent on each action in "Actions2"
+	ActionTimes []time.Duration
+
+	// Turn time limit related fields (for speedruns with a turn time limit)
+	// The clock of the active player is paused while they are disconnected
+	TurnTimeLimitCount         int // Incremented to invalidate the previous "CheckTurnTimeLimit()"
+	TurnTimeLimitPaused        bool
+	TurnTimeLimitRemaining     time.Duration
+	DatetimeTurnTimeLimitBegin time.Time
 
 	// Shared replay fields
 	EfficiencyMod int
@@ -125,6 +141,16 @@ func NewGame(t *Table) *Game {
 		Paused:           false,
 		PausePlayerIndex: -1,
 		PauseCount:       0,
+		DatetimePaused:   time.Time{},
+
+		TurnTimeBeforePause: 0,
+		TurnTimeBeforeSave:  0,
+		ActionTimes:         make([]time.Duration, 0),
+
+		TurnTimeLimitCount:         0,
+		TurnTimeLimitPaused:        false,
+		TurnTimeLimitRemaining:     0,
+		DatetimeTurnTimeLimitBegin: time.Time{},
 
 		EfficiencyMod: 0,
 
@@ -202,6 +228,179 @@ func (g *Game) CheckTimer(
 	g.EndTimer(ctx, gp)
 }
 
+// StartTurnTimeLimit starts the clock for the active player in a game with a turn time limit
+// If the active player is disconnected, the clock starts out paused
+// The table lock is assumed to be acquired in this function
+func (g *Game) StartTurnTimeLimit(ctx context.Context, timeLimit time.Duration) {
+	// Local variables
+	t := g.Table
+
+	g.TurnTimeLimitCount++
+	g.TurnTimeLimitRemaining = timeLimit
+	g.DatetimeTurnTimeLimitBegin = time.Now()
+	g.TurnTimeLimitPaused = false
+
+	if !t.Players[g.ActivePlayerIndex].Present {
+		g.PauseTurnTimeLimit(ctx)
+		return
+	}
+
+	go g.CheckTurnTimeLimit(ctx, timeLimit, g.Turn, g.TurnTimeLimitCount)
+}
+
+// PauseTurnTimeLimit stops the clock of the active player when they disconnect
+// They only have a grace period to reconnect; after that, they run out of time
+// (so that a player cannot stall the game forever by disconnecting)
+// The table lock is assumed to be acquired in this function
+func (g *Game) PauseTurnTimeLimit(ctx context.Context) {
+	if g.TurnTimeLimitPaused {
+		return
+	}
+
+	g.TurnTimeLimitCount++
+	g.TurnTimeLimitRemaining -= time.Since(g.DatetimeTurnTimeLimitBegin)
+	g.TurnTimeLimitPaused = true
+
+	go g.CheckTurnTimeLimit(ctx, TurnTimeLimitGracePeriod, g.Turn, g.TurnTimeLimitCount)
+}
+
+// ResumeTurnTimeLimit restarts the clock of the active player when they reconnect,
+// with however much time they had left when they disconnected
+// The table lock is assumed to be acquired in this function
+func (g *Game) ResumeTurnTimeLimit(ctx context.Context) {
+	if !g.TurnTimeLimitPaused {
+		return
+	}
+
+	g.StartTurnTimeLimit(ctx, g.TurnTimeLimitRemaining)
+}
+
+// CheckTurnTimeLimit is meant to be called in a new goroutine
+func (g *Game) CheckTurnTimeLimit(
+	ctx context.Context,
+	timeToSleep time.Duration,
+	turn int,
+	turnTimeLimitCount int,
+) {
+	// Sleep until the active player runs out of time
+	time.Sleep(timeToSleep)
+
+	// Local variables
+	t := g.Table
+
+	// Check to see if the table still exists
+	t2, exists := getTableAndLock(ctx, nil, t.ID, false, true)
+	if !exists || t != t2 {
+		return
+	}
+	t.Lock(ctx)
+	defer t.Unlock(ctx)
+
+	// Check to see if we have made a move in the meanwhile
+	if turn != g.Turn {
+		return
+	}
+
+	// Check to see if the clock was paused, resumed, or restarted while we were sleeping
+	if turnTimeLimitCount != g.TurnTimeLimitCount {
+		return
+	}
+
+	// Check to see if the game ended already
+	if g.EndCondition > EndConditionInProgress {
+		return
+	}
+
+	g.EndTurnTimeLimit(ctx)
+}
+
+// EndTurnTimeLimit automatically discards the newest card of the active player
+// If that is not possible (e.g. the team is at the maximum amount of clues),
+// the game ends in the same way as when a player runs out of time in a timed game
+func (g *Game) EndTurnTimeLimit(ctx context.Context) {
+	// Local variables
+	t := g.Table
+	variant := variants[g.Options.VariantName]
+	gp := g.Players[g.ActivePlayerIndex]
+
+	logger.Info(t.GetName() + "Turn time limit ran out for \"" + gp.Name + "\".")
+
+	// Get the session of this player
+	p := t.Players[gp.Index]
+	s := p.Session
+	if s == nil {
+		// A player's session should never be nil
+		// They might be in the process of reconnecting,
+		// so make a fake session that will represent them
+		s = NewFakeSession(p.UserID, p.Name)
+		logger.Info("Created a new fake session in the \"EndTurnTimeLimit()\" function.")
+	}
+
+	numActions := len(g.Actions2)
+	if len(gp.Hand) > 0 && !variant.AtMaxClueTokens(g.ClueTokens) {
+		commandAction(ctx, s, &CommandData{ // nolint: exhaustivestruct
+			TableID:     t.ID,
+			Type:        ActionTypeDiscard,
+			Target:      gp.Hand[len(gp.Hand)-1].Order,
+			Value:       DiscardValueTimeout,
+			NoTableLock: true,
+
+			TurnTimeLimitExpired: true,
+		})
+	}
+
+	// The discard can also fail because of "Detrimental Character Assignment" restrictions
+	if len(g.Actions2) == numActions && g.EndCondition == EndConditionInProgress {
+		commandAction(ctx, s, &CommandData{ // nolint: exhaustivestruct
+			TableID:     t.ID,
+			Type:        ActionTypeEndGame,
+			Target:      gp.Index,
+			Value:       EndConditionTimeout,
+			NoTableLock: true,
+		})
+	}
+}
+
+// Pause freezes the clock of the active player
+// Only the active player can pause the game (see the "commandPause()" function)
+// The table lock is assumed to be acquired in this function
+func (g *Game) Pause(playerIndex int) {
+	g.Paused = true
+	g.PausePlayerIndex = playerIndex
+	g.PauseCount++
+	g.DatetimePaused = time.Now()
+
+	// Decrement the time that the active player has taken so far prior to this pause
+	activePlayer := g.Players[g.ActivePlayerIndex]
+	elapsedTime := time.Since(g.DatetimeTurnBegin)
+	activePlayer.Time -= elapsedTime
+	g.TurnTimeBeforePause += elapsedTime
+}
+
+// Resume restarts the clock of the active player
+// The table lock is assumed to be acquired in this function
+func (g *Game) Resume() {
+	g.Paused = false
+	g.PausePlayerIndex = -1
+	g.DatetimePaused = time.Time{}
+
+	// Technically, a players turn should not begin when the game is unpaused,
+	// but this variable is only used for decrementing time taken at the end of a player's turn
+	g.DatetimeTurnBegin = time.Now()
+}
+
+// SaveTurnTime records the time that the active player has spent on the current turn so far
+// It must be called right before the game is serialized
+// The table lock is assumed to be acquired in this function
+func (g *Game) SaveTurnTime() {
+	if g.Paused {
+		// The time prior to the pause was already charged in the "Pause()" function
+		g.TurnTimeBeforeSave = 0
+	} else {
+		g.TurnTimeBeforeSave = time.Since(g.DatetimeTurnBegin)
+	}
+}
+
 // EndTimer is called when a player has run out of time in a timed game, which will automatically
 // end the game with a score of 0
 // The table lock is assumed to be acquired in this function
@@ -246,6 +445,8 @@ func (g *Game) CheckEnd() bool {
 		g.EndCondition == EndConditionTerminatedByPlayer ||
 		g.EndCondition == EndConditionTerminatedByVote ||
 		g.EndCondition == EndConditionIdleTimeout ||
+		g.EndCondition == EndConditionAbandoned ||
+		g.EndCondition == EndConditionResigned ||
 		g.EndCondition == EndConditionCharacterSoftlock {
 
 		return true
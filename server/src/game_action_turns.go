@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// getGameActionTurns emulates a database game and returns the in-game turn that each stored
+// action was performed on
+// This is necessary because the "turn" column of the "game_actions" table is really an index;
+// some characters (e.g. "Genius" and "Panicky") can perform two actions on the same turn
+// This acquires the tables lock, so it must only be called from a command or an HTTP handler that
+// does not already hold it (and never from the model layer)
+func getGameActionTurns(ctx context.Context, databaseID int) ([]int, error) {
+	s := NewFakeSession(-1, "Hanabi Live")
+
+	tables.Lock(ctx)
+	defer tables.Unlock(ctx)
+
+	t := NewTable("Turn lookup for game #"+strconv.Itoa(databaseID), -1)
+	t.Lock(ctx)
+	defer t.Unlock(ctx)
+	t.Visible = false
+
+	var dbPlayers []*DBPlayer
+	if v, success := loadDatabaseOptionsToTable(s, databaseID, t); !success {
+		return nil, errors.New("failed to load the options for game " + strconv.Itoa(databaseID))
+	} else {
+		dbPlayers = v
+	}
+
+	playerNames := make([]string, 0)
+	for _, dbPlayer := range dbPlayers {
+		playerNames = append(playerNames, dbPlayer.Name)
+	}
+	loadFakePlayers(t, playerNames)
+
+	// We perform the actions ourselves (instead of in the "emulateActions()" function)
+	// so that we can record the turn before each one
+	actions := t.ExtraOptions.CustomActions
+	t.ExtraOptions.CustomActions = nil
+
+	tables.Set(t.ID, t)
+	defer deleteTable(t)
+
+	commandTableStart(ctx, t.Players[0].Session, &CommandData{ // nolint: exhaustivestruct
+		TableID:      t.ID,
+		NoTableLock:  true,
+		NoTablesLock: true,
+	})
+	g := t.Game
+	if g == nil {
+		return nil, errors.New("failed to start the game for game " + strconv.Itoa(databaseID))
+	}
+
+	turns := make([]int, 0, len(actions))
+	for i, action := range actions {
+		turns = append(turns, g.Turn)
+
+		p := t.Players[g.ActivePlayerIndex]
+		commandAction(ctx, p.Session, &CommandData{ // nolint: exhaustivestruct
+			TableID:      t.ID,
+			Type:         action.Type,
+			Target:       action.Target,
+			Value:        action.Value,
+			NoTableLock:  true,
+			NoTablesLock: true,
+
+			TurnTimeLimitExpired: action.Type == ActionTypeDiscard &&
+				action.Value == DiscardValueTimeout,
+			EndedByServer: action.Type == ActionTypeEndGame ||
+				action.Type == ActionTypeEndGameByVote,
+		})
+
+		if g.InvalidActionOccurred {
+			return nil, errors.New("the action at index " + strconv.Itoa(i) + " for game " +
+				strconv.Itoa(databaseID) + " was not valid")
+		}
+	}
+
+	return turns, nil
+}
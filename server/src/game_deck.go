@@ -82,10 +82,22 @@ func numCopiesOfCard(suit *Suit, rank int, variant *Variant) int {
 	return 2
 }
 
+// ShuffleDeck shuffles the deck based on the seed of the game
+// The shuffle algorithm is pinned; changing any part of it would change the deal of every seed
+// (and old games would no longer match the seeds that they were played on)
+// 1) The seed is converted to an int64 with the "getSeedInt64()" function (a CRC64 checksum)
+// 2) A dedicated "math/rand" source is created from that number
+// (the sequence of a seeded "math/rand" source is covered by the Go 1 compatibility promise)
+// 3) The cards are swapped from the front of the deck with "Intn(i + 1)" (a Fisher-Yates shuffle)
+// We do not use the global random number generator because other goroutines can use it in the
+// middle of the shuffle (e.g. "getRandom()"), which would result in a different deal
+// Any change to this function must keep the hashes from "Games.VerifySeedDeterminism()" the same
 func (g *Game) ShuffleDeck() {
+	r := rand.New(rand.NewSource(getSeedInt64(g.Seed))) // nolint: gosec
+
 	// From: https://stackoverflow.com/questions/12264789/shuffle-array-in-go
 	for i := range g.Deck {
-		j := rand.Intn(i + 1) // nolint: gosec
+		j := r.Intn(i + 1)
 		g.Deck[i], g.Deck[j] = g.Deck[j], g.Deck[i]
 		g.CardIdentities[i], g.CardIdentities[j] = g.CardIdentities[j], g.CardIdentities[i]
 	}
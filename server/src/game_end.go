@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"math"
 	"strconv"
 	"time"
 
@@ -61,6 +63,12 @@ func (g *Game) End(ctx context.Context, d *CommandData) {
 		return
 	}
 
+	// The game is over, so it should not be restored if the server crashes
+	if err := models.Games.DeleteCheckpoint(t.ID); err != nil {
+		logger.Error("Failed to delete the checkpoint for table " + strconv.FormatUint(t.ID, 10) +
+			": " + err.Error())
+	}
+
 	// Send a "gameHistory" message to all the players in the game
 	var numGamesOnThisSeed int
 	if v, err := models.Seeds.GetNumGames(g.Seed); err != nil {
@@ -113,6 +121,7 @@ func (g *Game) WriteDatabase() error {
 		Seed:             g.Seed,
 		Score:            g.Score,
 		NumTurns:         g.Turn,
+		NumStrikes:       g.Strikes,
 		EndCondition:     g.EndCondition,
 		DatetimeStarted:  g.DatetimeStarted,
 		DatetimeFinished: g.DatetimeFinished,
@@ -130,7 +139,7 @@ func (g *Game) WriteDatabase() error {
 		p := t.Players[gp.Index]
 
 		characterID := 0
-		characterMetadata := 0
+		characterMetadata := NewDBCharacterMetadata(-1)
 		if t.Options.DetrimentalCharacters {
 			if gp.Character == "n/a" {
 				characterID = -1
@@ -143,15 +152,11 @@ func (g *Game) WriteDatabase() error {
 				} else {
 					characterID = v.ID
 
-					// -1 is considered to be "null" metadata
-					// Since most characters have null metadata,
-					// we save space in the database by storing -1 as 0
-					// However, if some characters have a metadata of 0, it is meaningful
-					// (e.g. corresponding to the 0th suit)
-					// Thus, we store meaningful metadata in the database as 1 + the value to make
-					// it clear that it is not a null value
+					// Most characters have null metadata,
+					// so we only write it for the characters that need it in order to replay
+					// the game
 					if v.WriteMetadataToDatabase {
-						characterMetadata = gp.CharacterMetadata + 1
+						characterMetadata = NewDBCharacterMetadata(gp.CharacterMetadata)
 					}
 				}
 			}
@@ -163,9 +168,19 @@ func (g *Game) WriteDatabase() error {
 			Seat:                gp.Index,
 			CharacterAssignment: characterID,
 			CharacterMetadata:   characterMetadata,
+			DatetimeDisconnected: sql.NullTime{
+				Time:  p.DatetimeDisconnected,
+				Valid: !p.DatetimeDisconnected.IsZero(),
+			},
+			Username: p.Name,
 		})
 	}
-	if err := models.GameParticipants.BulkInsert(gameParticipantsRows); err != nil {
+	if err := models.GameParticipants.BatchInsert(
+		t.ExtraOptions.DatabaseID,
+		t.Options.DetrimentalCharacters,
+		t.Options.AllowedCharacters,
+		gameParticipantsRows,
+	); err != nil {
 		logger.Error("Failed to insert the game participant rows: " + err.Error())
 		return err
 	}
@@ -173,12 +188,24 @@ func (g *Game) WriteDatabase() error {
 	// Next, we insert rows for each of the actions
 	gameActionRows := make([]*GameActionRow, 0)
 	for i, action := range g.Actions2 {
+		thinkTime := sql.NullInt32{} // nolint: exhaustivestruct
+		if action.Type != ActionTypeEndGame &&
+			action.Type != ActionTypeEndGameByVote &&
+			i < len(g.ActionTimes) {
+
+			thinkTime = sql.NullInt32{
+				Int32: int32(g.ActionTimes[i] / time.Millisecond),
+				Valid: true,
+			}
+		}
+
 		gameActionRows = append(gameActionRows, &GameActionRow{
-			GameID: t.ExtraOptions.DatabaseID,
-			Turn:   i,
-			Type:   action.Type,
-			Target: action.Target,
-			Value:  action.Value,
+			GameID:    t.ExtraOptions.DatabaseID,
+			Turn:      i,
+			Type:      action.Type,
+			Target:    action.Target,
+			Value:     action.Value,
+			ThinkTime: thinkTime,
 		})
 	}
 	if len(gameActionRows) > 0 {
@@ -215,12 +242,15 @@ func (g *Game) WriteDatabase() error {
 	}
 
 	// Next, we insert rows for each chat message (if any)
+	// They are written to the room of the game (instead of the room of the table) so that the
+	// pregame chat can be seen in the replay (with the "ChatLog.GetByGame()" function)
 	chatLogRows := make([]*ChatLogRow, 0)
 	for _, chatMsg := range t.Chat {
 		chatLogRows = append(chatLogRows, &ChatLogRow{
-			UserID:  chatMsg.UserID,
-			Message: chatMsg.Msg,
-			Room:    t.GetRoomName(),
+			UserID:   chatMsg.UserID,
+			Message:  chatMsg.Msg,
+			Room:     getGameRoomName(t.ExtraOptions.DatabaseID),
+			Datetime: chatMsg.Datetime,
 		})
 	}
 	if len(chatLogRows) > 0 {
@@ -257,6 +287,13 @@ func (g *Game) WriteDatabase() error {
 
 	// We also need to update stats in the database, but that can be done in the background
 	go g.WriteDatabaseStats()
+	userIDs := make([]int, 0, len(g.Players))
+	for _, gp := range g.Players {
+		userIDs = append(userIDs, t.Players[gp.Index].UserID)
+	}
+	actions := make([]*GameAction, len(g.Actions2))
+	copy(actions, g.Actions2)
+	go writeGameEvents(t.ExtraOptions.DatabaseID, t.Options, g.Score, userIDs, actions)
 
 	logger.Info("Finished core database actions for table " + strconv.FormatUint(t.ID, 10) +
 		" (to database ID " + strconv.Itoa(t.ExtraOptions.DatabaseID) + ").")
@@ -331,6 +368,90 @@ func (g *Game) WriteDatabaseStats() {
 			err.Error())
 		return
 	}
+
+	g.WriteDatabaseRatings()
+}
+
+// WriteDatabaseRatings adjusts the rating of every player for the variant and number of players
+// that the game was played with
+// The team "wins" if they beat the average score of the previous games in the same bucket
+// and "loses" if they do worse; a team with a higher rating is expected to win more often
+func (g *Game) WriteDatabaseRatings() {
+	// Local variables
+	t := g.Table
+	variant := variants[g.Options.VariantName]
+
+	if !g.IsRated() {
+		return
+	}
+
+	var expectedScore float64
+	var numPreviousGames int
+	if v1, v2, err := models.UserRatings.GetExpectedScore(
+		variant.ID,
+		g.Options.NumPlayers,
+		t.ExtraOptions.DatabaseID,
+	); err != nil {
+		logger.Error("Failed to get the expected score for variant " + strconv.Itoa(variant.ID) +
+			": " + err.Error())
+		return
+	} else {
+		expectedScore = v1
+		numPreviousGames = v2
+	}
+
+	userRatingsRows := make([]*UserRatingsRow, 0)
+	teamRating := 0.0
+	for _, p := range t.Players {
+		if v, err := models.UserRatings.Get(p.UserID, variant.ID, g.Options.NumPlayers); err != nil {
+			logger.Error("Failed to get the rating for user " + p.Name + ": " + err.Error())
+			return
+		} else {
+			userRatingsRows = append(userRatingsRows, v)
+			teamRating += v.Rating
+		}
+	}
+	teamRating /= float64(len(userRatingsRows))
+
+	// With no previous games, there is nothing to compare against, so it counts as a draw
+	outcome := 0.5
+	if numPreviousGames > 0 {
+		if float64(g.Score) > expectedScore {
+			outcome = 1
+		} else if float64(g.Score) < expectedScore {
+			outcome = 0
+		}
+	}
+	expectedOutcome := 1 / (1 + math.Pow(10, (DefaultRating-teamRating)/400))
+	ratingChange := RatingKFactor * (outcome - expectedOutcome)
+
+	for _, userRatingsRow := range userRatingsRows {
+		if err := models.UserRatings.Update(
+			userRatingsRow.UserID,
+			variant.ID,
+			g.Options.NumPlayers,
+			userRatingsRow.Rating+ratingChange,
+		); err != nil {
+			logger.Error("Failed to update the rating for user " +
+				strconv.Itoa(userRatingsRow.UserID) + ": " + err.Error())
+			continue
+		}
+	}
+}
+
+// IsRated returns false for games that should not affect the ratings of the players
+// (e.g. games with custom options or games that were not played to completion)
+func (g *Game) IsRated() bool {
+	return !g.Options.Speedrun &&
+		g.Options.GetModifier() == 0 &&
+		!g.Options.DetrimentalCharacters &&
+		g.ExtraOptions.CustomSeed == "" &&
+		g.ExtraOptions.SetSeedSuffix == "" &&
+		g.EndCondition != EndConditionTerminatedByPlayer &&
+		g.EndCondition != EndConditionIdleTimeout &&
+		g.EndCondition != EndConditionTerminatedByVote &&
+		g.EndCondition != EndConditionAbandoned &&
+		g.EndCondition != EndConditionResigned
 }
 
 func (t *Table) ConvertToSharedReplay(ctx context.Context, d *CommandData) {
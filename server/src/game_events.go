@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+// These are the values for the "event_type" column of the "game_events" table
+const (
+	// Every player in a game that reached the maximum score
+	GameEventPerfectScore = "perfectScore"
+	// The player who made the final play of a game that reached the maximum score,
+	// if that play was made on the very last turn that the game allowed
+	// (i.e. the last player to take a turn after the final card was drawn)
+	GameEventClutchPlay = "clutchPlay"
+)
+
+// DetectGameEvents looks through a game and returns the events that happened in it
+// "userIDs" must be in seat order
+// It does not read from or write to the database, so the caller is responsible for inserting the
+// events (with the "GameEvents.Insert()" function)
+func DetectGameEvents(
+	gameID int,
+	options *Options,
+	score int,
+	userIDs []int,
+	actions []*GameAction,
+) ([]GameEvent, error) {
+	events := make([]GameEvent, 0)
+
+	var variant *Variant
+	if v, ok := variants[options.VariantName]; !ok {
+		return events, errors.New("\"" + options.VariantName + "\" is not a valid variant")
+	} else {
+		variant = v
+	}
+
+	numPlayers := len(userIDs)
+	var handSize int
+	if v, ok := DefaultNumCardsPerHand[numPlayers]; !ok {
+		return events, errors.New("game " + strconv.Itoa(gameID) + " has an invalid amount of " +
+			"players: " + strconv.Itoa(numPlayers))
+	} else {
+		handSize = v
+	}
+	if options.OneExtraCard {
+		handSize++
+	}
+	if options.OneLessCard {
+		handSize--
+	}
+
+	if score != variant.MaxScore {
+		return events, nil
+	}
+
+	for _, userID := range userIDs {
+		events = append(events, GameEvent{
+			GameID:    gameID,
+			UserID:    userID,
+			EventType: GameEventPerfectScore,
+		})
+	}
+
+	// Characters can change whose turn it is (e.g. "Genius"),
+	// so we can only figure out who performed each action in games without characters
+	// "All or Nothing" games do not end after the final card is drawn,
+	// so there is no last turn to make a clutch play on
+	if options.DetrimentalCharacters || options.AllOrNothing {
+		return events, nil
+	}
+
+	// Find the index of the final action that the game allowed
+	// Every play and discard draws a card until the deck runs out,
+	// and then each player gets one more turn
+	cardsLeft := variant.GetDeckSize() - handSize*numPlayers
+	lastAllowedAction := -1
+	for i, action := range actions {
+		if action.Type != ActionTypePlay && action.Type != ActionTypeDiscard {
+			continue
+		}
+		if cardsLeft <= 0 {
+			break
+		}
+		cardsLeft--
+		if cardsLeft == 0 {
+			lastAllowedAction = i + numPlayers
+			break
+		}
+	}
+	if lastAllowedAction == -1 {
+		return events, nil
+	}
+
+	// Find the final action before the "game over" action (if any)
+	for i := len(actions) - 1; i >= 0; i-- {
+		action := actions[i]
+		if action.Type == ActionTypeEndGame || action.Type == ActionTypeEndGameByVote {
+			continue
+		}
+
+		if action.Type == ActionTypePlay && i == lastAllowedAction {
+			playerIndex := (options.StartingPlayer + i) % numPlayers
+			events = append(events, GameEvent{
+				GameID:    gameID,
+				UserID:    userIDs[playerIndex],
+				EventType: GameEventClutchPlay,
+			})
+		}
+		break
+	}
+
+	return events, nil
+}
+
+// writeGameEvents detects the events for a game that was just written to the database and
+// inserts them
+func writeGameEvents(
+	gameID int,
+	options *Options,
+	score int,
+	userIDs []int,
+	actions []*GameAction,
+) {
+	var events []GameEvent
+	if v, err := DetectGameEvents(gameID, options, score, userIDs, actions); err != nil {
+		logger.Error("Failed to detect the events for game " + strconv.Itoa(gameID) + ": " +
+			err.Error())
+		return
+	} else {
+		events = v
+	}
+
+	for _, event := range events {
+		if err := models.GameEvents.Insert(event.GameID, event.UserID, event.EventType); err != nil {
+			logger.Error("Failed to insert the \"" + event.EventType + "\" event for game " +
+				strconv.Itoa(gameID) + ": " + err.Error())
+		}
+	}
+}
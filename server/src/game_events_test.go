@@ -0,0 +1,196 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// newTestGameActions returns a game that starts with the given amount of plays,
+// followed by the given actions
+func newTestGameActions(numPlays int, finalActionTypes ...int) []*GameAction {
+	actions := make([]*GameAction, 0, numPlays+len(finalActionTypes))
+	for i := 0; i < numPlays; i++ {
+		actions = append(actions, &GameAction{Type: ActionTypePlay}) // nolint: exhaustivestruct
+	}
+	for _, actionType := range finalActionTypes {
+		actions = append(actions, &GameAction{Type: actionType}) // nolint: exhaustivestruct
+	}
+
+	return actions
+}
+
+func TestDetectGameEvents(t *testing.T) {
+	const gameID = 1
+	const maxScore = 25 // "No Variant" has 5 suits
+
+	perfectScoreEvents := func(userIDs ...int) []GameEvent {
+		events := make([]GameEvent, 0, len(userIDs))
+		for _, userID := range userIDs {
+			events = append(events, GameEvent{
+				GameID:    gameID,
+				UserID:    userID,
+				EventType: GameEventPerfectScore,
+			})
+		}
+		return events
+	}
+	withClutchPlay := func(events []GameEvent, userID int) []GameEvent {
+		return append(events, GameEvent{
+			GameID:    gameID,
+			UserID:    userID,
+			EventType: GameEventClutchPlay,
+		})
+	}
+
+	// In a 2-player game of "No Variant", there are 40 cards left in the deck after the initial
+	// deal, so the final card is drawn on action 39 and the final turn is action 41
+	tests := []struct {
+		name    string
+		options func(*Options)
+		score   int
+		userIDs []int
+		actions []*GameAction
+		want    []GameEvent
+	}{
+		{
+			name:    "not a perfect score",
+			score:   maxScore - 1,
+			userIDs: []int{1, 2},
+			actions: newTestGameActions(40, ActionTypeColorClue, ActionTypePlay),
+			want:    []GameEvent{},
+		},
+		{
+			name:    "perfect score with a clutch play on the last allowed turn",
+			score:   maxScore,
+			userIDs: []int{1, 2},
+			actions: newTestGameActions(40, ActionTypeColorClue, ActionTypePlay),
+			want:    withClutchPlay(perfectScoreEvents(1, 2), 2),
+		},
+		{
+			name:    "clutch play followed by the game over action",
+			score:   maxScore,
+			userIDs: []int{1, 2},
+			actions: newTestGameActions(40, ActionTypeColorClue, ActionTypePlay, ActionTypeEndGame),
+			want:    withClutchPlay(perfectScoreEvents(1, 2), 2),
+		},
+		{
+			name:    "final play before the last allowed turn",
+			score:   maxScore,
+			userIDs: []int{1, 2},
+			actions: newTestGameActions(41),
+			want:    perfectScoreEvents(1, 2),
+		},
+		{
+			name:    "final play before the deck runs out",
+			score:   maxScore,
+			userIDs: []int{1, 2},
+			actions: newTestGameActions(30),
+			want:    perfectScoreEvents(1, 2),
+		},
+		{
+			name:    "clue on the last allowed turn",
+			score:   maxScore,
+			userIDs: []int{1, 2},
+			actions: newTestGameActions(40, ActionTypeColorClue, ActionTypeRankClue),
+			want:    perfectScoreEvents(1, 2),
+		},
+		{
+			name: "clutch play with a different starting player",
+			options: func(options *Options) {
+				options.StartingPlayer = 1
+			},
+			score:   maxScore,
+			userIDs: []int{1, 2},
+			actions: newTestGameActions(40, ActionTypeColorClue, ActionTypePlay),
+			want:    withClutchPlay(perfectScoreEvents(1, 2), 1),
+		},
+		{
+			// There are 35 cards left in the deck, so the final turn is action 37
+			name:    "clutch play with 3 players",
+			score:   maxScore,
+			userIDs: []int{1, 2, 3},
+			actions: newTestGameActions(35, ActionTypeColorClue, ActionTypeColorClue, ActionTypePlay),
+			want:    withClutchPlay(perfectScoreEvents(1, 2, 3), 2),
+		},
+		{
+			// There are 38 cards left in the deck, so the final turn is action 39
+			name: "clutch play with one extra card",
+			options: func(options *Options) {
+				options.OneExtraCard = true
+			},
+			score:   maxScore,
+			userIDs: []int{1, 2},
+			actions: newTestGameActions(38, ActionTypeColorClue, ActionTypePlay),
+			want:    withClutchPlay(perfectScoreEvents(1, 2), 2),
+		},
+		{
+			name: "detrimental characters",
+			options: func(options *Options) {
+				options.DetrimentalCharacters = true
+			},
+			score:   maxScore,
+			userIDs: []int{1, 2},
+			actions: newTestGameActions(40, ActionTypeColorClue, ActionTypePlay),
+			want:    perfectScoreEvents(1, 2),
+		},
+		{
+			name: "all or nothing",
+			options: func(options *Options) {
+				options.AllOrNothing = true
+			},
+			score:   maxScore,
+			userIDs: []int{1, 2},
+			actions: newTestGameActions(40, ActionTypeColorClue, ActionTypePlay),
+			want:    perfectScoreEvents(1, 2),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			options := NewOptions()
+			if test.options != nil {
+				test.options(options)
+			}
+
+			events, err := DetectGameEvents(gameID, options, test.score, test.userIDs, test.actions)
+			if err != nil {
+				t.Fatalf("failed to detect the game events: %v", err)
+			}
+			if !reflect.DeepEqual(events, test.want) {
+				t.Errorf("got %v, want %v", events, test.want)
+			}
+		})
+	}
+}
+
+func TestDetectGameEventsErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		variantName string
+		userIDs     []int
+	}{
+		{
+			name:        "invalid variant",
+			variantName: "Not A Real Variant",
+			userIDs:     []int{1, 2},
+		},
+		{
+			name:        "invalid amount of players",
+			variantName: DefaultVariantName,
+			userIDs:     []int{1},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			options := NewOptions()
+			options.VariantName = test.variantName
+
+			if _, err := DetectGameEvents(1, options, 25, test.userIDs, nil); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -1,5 +1,10 @@
 package main
 
+import (
+	"hash/crc64"
+	"strconv"
+)
+
 type GameJSON struct {
 	ID      int             `json:"id,omitempty"` // Optional element only used for game exports
 	Players []string        `json:"players"`
@@ -23,3 +28,77 @@ type CharacterAssignment struct {
 	Name     string `json:"name"`
 	Metadata int    `json:"metadata"`
 }
+
+// GameJSONDefinition is a game that is being imported from a JSON deck definition (e.g. a puzzle)
+// The results of the game (e.g. the score) are derived by emulating the actions beforehand
+type GameJSONDefinition struct {
+	GameRow  GameRow
+	GameJSON *GameJSON
+	// UserIDs corresponds to the players in the JSON (in seat order)
+	UserIDs []int
+}
+
+// isJSONDeckValid checks that the deck contains exactly the cards that a normal game of the
+// variant would have
+// (the "isJSONValid()" function only checks the size of the deck,
+// since hypotheticals from arbitrary JSON are allowed to have any cards in them)
+func isJSONDeckValid(variant *Variant, deck []*CardIdentity) (bool, string) {
+	deckSize := variant.GetDeckSize()
+	if len(deck) != deckSize {
+		msg := "The deck must have " + strconv.Itoa(deckSize) + " cards in it."
+		return false, msg
+	}
+
+	// Count how many of each card there are in the deck
+	cardCounts := make(map[CardIdentity]int)
+	for i, card := range deck {
+		if card.SuitIndex < 0 || card.SuitIndex > len(variant.Suits)-1 {
+			msg := "The card at index " + strconv.Itoa(i) +
+				" has an invalid suit number of " + strconv.Itoa(card.SuitIndex) + "."
+			return false, msg
+		}
+		if !intInSlice(card.Rank, variant.Ranks) {
+			msg := "The card at index " + strconv.Itoa(i) +
+				" has an invalid rank number of " + strconv.Itoa(card.Rank) + "."
+			return false, msg
+		}
+		cardCounts[*card]++
+	}
+
+	for suitIndex, suit := range variant.Suits {
+		for _, rank := range variant.Ranks {
+			expected := numCopiesOfCard(suit, rank, variant)
+			actual := cardCounts[CardIdentity{
+				SuitIndex: suitIndex,
+				Rank:      rank,
+			}]
+			if actual != expected {
+				rankName := strconv.Itoa(rank)
+				if rank == StartCardRank {
+					rankName = "START"
+				}
+				msg := "The deck has " + strconv.Itoa(actual) + " copies of " + suit.Name + " " +
+					rankName + ", but there should be " + strconv.Itoa(expected) + "."
+				return false, msg
+			}
+		}
+	}
+
+	return true, ""
+}
+
+// getJSONDeckSeed returns a seed that identifies a deck that was imported from JSON
+// It mirrors the format of normal seeds (e.g. "p2v0s1"),
+// but uses a "j" so that it can never collide with a seed that the server generates
+// (identical decks will result in identical seeds)
+func getJSONDeckSeed(numPlayers int, variantID int, deck []*CardIdentity) string {
+	deckString := ""
+	for _, card := range deck {
+		deckString += strconv.Itoa(card.SuitIndex) + "," + strconv.Itoa(card.Rank) + ";"
+	}
+	crc64Table := crc64.MakeTable(crc64.ECMA)
+	checksum := crc64.Checksum([]byte(deckString), crc64Table)
+
+	return "p" + strconv.Itoa(numPlayers) + "v" + strconv.Itoa(variantID) + "j" +
+		strconv.FormatUint(checksum, 16)
+}
@@ -218,10 +218,15 @@ func (p *GamePlayer) DiscardCard(c *Card) {
 	// (in the future, we will delete GameActions and only keep track of GameActions2)
 	if !c.Failed {
 		// If this is a failed play, then we already added the action in the "PlayCard()"" function
+		// The value is only used to mark discards that were caused by running out of time
+		value := DiscardValueNormal
+		if c.TimedOut {
+			value = DiscardValueTimeout
+		}
 		g.Actions2 = append(g.Actions2, &GameAction{
 			Type:   ActionTypeDiscard,
 			Target: c.Order,
-			Value:  0, // This is unused for discard actions
+			Value:  value,
 		})
 	}
 
@@ -235,6 +240,7 @@ func (p *GamePlayer) DiscardCard(c *Card) {
 		Rank:        c.Rank,
 		SuitIndex:   c.SuitIndex,
 		Failed:      c.Failed,
+		Timeout:     c.TimedOut,
 	})
 	t.NotifyGameAction()
 
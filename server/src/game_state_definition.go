@@ -0,0 +1,429 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// GameStateDefinition describes a game that is in progress
+// It is used by the "Games.InsertWithState()" function to set up specific situations for testing
+type GameStateDefinition struct {
+	// Only the name and the options are used; the other fields are derived from the state
+	GameRow GameRow
+	Players []string
+	// UserIDs corresponds to the players (in seat order)
+	UserIDs []int
+
+	// The cards in each player's hand, in the same order as "GamePlayer.Hand"
+	// (i.e. the oldest card is first and the newest card is last)
+	Hands [][]*CardIdentity
+	// The order of the discard pile is not preserved,
+	// since some of the cards will be misplayed in order to get the right amount of strikes
+	DiscardPile []*CardIdentity
+	// The amount of cards that have been played on each stack
+	Stacks     []int
+	ClueTokens int
+	Strikes    int
+}
+
+type gameStateStep struct {
+	Card       *CardIdentity
+	ActionType int
+	Misplay    bool
+}
+
+type gameStateClue struct {
+	ActionIndex int
+	TargetHand  []int
+}
+
+// getGameStateJSON builds a deck and a plausible list of actions that will result in the state
+// from the definition
+// The players either give clues or act on their oldest card; the deck is arranged so that the
+// oldest card is always the one that needs to be played or discarded next
+func getGameStateJSON(def GameStateDefinition) (*GameJSON, error) {
+	// Validate everything that we can before building the deck
+	var variant *Variant
+	if v, ok := variants[def.GameRow.Options.VariantName]; !ok {
+		return nil, errors.New("the variant of \"" + def.GameRow.Options.VariantName +
+			"\" does not exist")
+	} else {
+		variant = v
+	}
+	if variant.HasReversedSuits() || variant.IsSudoku() || variant.IsThrowItInAHole() {
+		return nil, errors.New("the variant of \"" + variant.Name + "\" is not supported")
+	}
+	if def.GameRow.Options.DetrimentalCharacters {
+		return nil, errors.New("games with detrimental characters are not supported")
+	}
+
+	numPlayers := len(def.Players)
+	var handSize int
+	if v, ok := DefaultNumCardsPerHand[numPlayers]; !ok {
+		return nil, errors.New("the number of players must be between 2 and 6")
+	} else {
+		handSize = v
+	}
+	if def.GameRow.Options.OneExtraCard {
+		handSize++
+	}
+	if def.GameRow.Options.OneLessCard {
+		handSize--
+	}
+
+	if len(def.Hands) != numPlayers {
+		return nil, errors.New("there are " + strconv.Itoa(numPlayers) + " players but " +
+			strconv.Itoa(len(def.Hands)) + " hands")
+	}
+	if len(def.Stacks) != len(variant.Suits) {
+		return nil, errors.New("there are " + strconv.Itoa(len(variant.Suits)) + " suits but " +
+			strconv.Itoa(len(def.Stacks)) + " stacks")
+	}
+	if def.Strikes < 0 || def.Strikes >= MaxStrikeNum {
+		return nil, errors.New("the amount of strikes must be between 0 and " +
+			strconv.Itoa(MaxStrikeNum-1))
+	}
+	maxClueTokens := variant.GetAdjustedClueTokens(MaxClueNum)
+	clueCost := variant.GetAdjustedClueTokens(1)
+	if def.ClueTokens < 0 || def.ClueTokens > maxClueTokens {
+		return nil, errors.New("the amount of clue tokens must be between 0 and " +
+			strconv.Itoa(maxClueTokens))
+	}
+	for _, card := range def.DiscardPile {
+		if card.SuitIndex < 0 || card.SuitIndex > len(variant.Suits)-1 {
+			return nil, errors.New("a card in the discard pile has an invalid suit index of " +
+				strconv.Itoa(card.SuitIndex))
+		}
+	}
+
+	// Misplays have to be performed at a time when the card is not playable
+	// Anything other than a 1 is not playable before anything else is played,
+	// and a 1 is not playable after its stack has been started
+	earlyMisplays := make([]*gameStateStep, 0)
+	lateMisplays := make([]*gameStateStep, 0)
+	discards := make([]*gameStateStep, 0)
+	for _, card := range def.DiscardPile {
+		numMisplays := len(earlyMisplays) + len(lateMisplays)
+		if numMisplays < def.Strikes && card.Rank != 1 {
+			earlyMisplays = append(earlyMisplays, &gameStateStep{
+				Card:       card,
+				ActionType: ActionTypePlay,
+				Misplay:    true,
+			})
+		} else if numMisplays < def.Strikes && def.Stacks[card.SuitIndex] > 0 {
+			lateMisplays = append(lateMisplays, &gameStateStep{
+				Card:       card,
+				ActionType: ActionTypePlay,
+				Misplay:    true,
+			})
+		} else {
+			discards = append(discards, &gameStateStep{
+				Card:       card,
+				ActionType: ActionTypeDiscard,
+				Misplay:    false,
+			})
+		}
+	}
+	if len(earlyMisplays)+len(lateMisplays) < def.Strikes {
+		return nil, errors.New("there are not enough cards in the discard pile that can be " +
+			"misplayed to get " + strconv.Itoa(def.Strikes) + " strikes")
+	}
+
+	plays := make([]*gameStateStep, 0)
+	for suitIndex, stack := range def.Stacks {
+		if stack < 0 || stack > variant.StackSize {
+			return nil, errors.New("the stack at index " + strconv.Itoa(suitIndex) +
+				" must have between 0 and " + strconv.Itoa(variant.StackSize) + " cards")
+		}
+		for rank := 1; rank <= stack; rank++ {
+			plays = append(plays, &gameStateStep{
+				Card: &CardIdentity{
+					SuitIndex: suitIndex,
+					Rank:      rank,
+				},
+				ActionType: ActionTypePlay,
+				Misplay:    false,
+			})
+		}
+	}
+
+	steps := make([]*gameStateStep, 0)
+	steps = append(steps, earlyMisplays...)
+	steps = append(steps, plays...)
+	steps = append(steps, lateMisplays...)
+	steps = append(steps, discards...)
+
+	// Deal the cards in the same way that the "commandTableStart()" function does
+	// (we only keep track of the card orders at this point, since the deck is not built yet)
+	deckSize := variant.GetDeckSize()
+	deck := make([]*CardIdentity, deckSize)
+	deckIndex := 0
+	hands := make([][]int, numPlayers)
+	for i := range hands {
+		hands[i] = make([]int, 0)
+		for j := 0; j < handSize; j++ {
+			hands[i] = append(hands[i], deckIndex)
+			deckIndex++
+		}
+	}
+
+	actions := make([]*GameAction, 0)
+	clues := make([]*gameStateClue, 0)
+	activePlayerIndex := 0
+	clueTokens := maxClueTokens
+
+	// The clues are filled in later on, once we know what cards are in the hands
+	giveClue := func() {
+		targetIndex := (activePlayerIndex + 1) % numPlayers
+		targetHand := make([]int, len(hands[targetIndex]))
+		copy(targetHand, hands[targetIndex])
+		clues = append(clues, &gameStateClue{
+			ActionIndex: len(actions),
+			TargetHand:  targetHand,
+		})
+		actions = append(actions, &GameAction{
+			Type:   ActionTypeRankClue,
+			Target: targetIndex,
+			Value:  0,
+		})
+		clueTokens -= clueCost
+		activePlayerIndex = targetIndex
+	}
+
+	for _, step := range steps {
+		// Players are not allowed to discard at the maximum amount of clue tokens
+		if step.ActionType == ActionTypeDiscard && variant.AtMaxClueTokens(clueTokens) {
+			giveClue()
+		}
+
+		hand := hands[activePlayerIndex]
+		if len(hand) == 0 {
+			return nil, errors.New("player " + strconv.Itoa(activePlayerIndex) +
+				" ran out of cards before the state could be reached")
+		}
+		order := hand[0]
+		hands[activePlayerIndex] = hand[1:]
+		deck[order] = step.Card
+		actions = append(actions, &GameAction{
+			Type:   step.ActionType,
+			Target: order,
+			Value:  0,
+		})
+
+		if step.ActionType == ActionTypeDiscard {
+			clueTokens++
+		} else if !step.Misplay &&
+			step.Card.Rank == variant.StackSize &&
+			!variant.AtMaxClueTokens(clueTokens) &&
+			variant.ShouldGiveClueTokenForFinishingStack() {
+
+			clueTokens++
+		}
+
+		if deckIndex < deckSize {
+			hands[activePlayerIndex] = append(hands[activePlayerIndex], deckIndex)
+			deckIndex++
+		}
+		activePlayerIndex = (activePlayerIndex + 1) % numPlayers
+	}
+
+	for clueTokens-clueCost >= def.ClueTokens {
+		giveClue()
+	}
+	if clueTokens != def.ClueTokens {
+		return nil, errors.New("it is not possible to end up with " +
+			strconv.Itoa(def.ClueTokens) + " clue tokens")
+	}
+
+	// Put the cards from the definition into the hands
+	for i, hand := range hands {
+		if len(hand) != len(def.Hands[i]) {
+			return nil, errors.New("player " + strconv.Itoa(i) + " must have " +
+				strconv.Itoa(len(hand)) + " cards in their hand")
+		}
+		for j, order := range hand {
+			deck[order] = def.Hands[i][j]
+		}
+	}
+
+	// The rest of the deck consists of the cards that are left over
+	cardCounts := make(map[CardIdentity]int)
+	for suitIndex, suit := range variant.Suits {
+		for _, rank := range variant.Ranks {
+			cardCounts[CardIdentity{
+				SuitIndex: suitIndex,
+				Rank:      rank,
+			}] = numCopiesOfCard(suit, rank, variant)
+		}
+	}
+	for _, card := range deck[:deckIndex] {
+		cardCounts[*card]--
+		if cardCounts[*card] < 0 {
+			return nil, errors.New("there are too many copies of the card with a suit index of " +
+				strconv.Itoa(card.SuitIndex) + " and a rank of " + strconv.Itoa(card.Rank))
+		}
+	}
+	for suitIndex := range variant.Suits {
+		for _, rank := range variant.Ranks {
+			card := CardIdentity{
+				SuitIndex: suitIndex,
+				Rank:      rank,
+			}
+			for i := 0; i < cardCounts[card]; i++ {
+				leftoverCard := card
+				deck[deckIndex] = &leftoverCard
+				deckIndex++
+			}
+		}
+	}
+
+	// Now that the deck is built, pick a clue for each clue action that touches at least one card
+	for _, clue := range clues {
+		action := actions[clue.ActionIndex]
+		if v, ok := getGameStateClue(variant, deck, clue.TargetHand); !ok {
+			return nil, errors.New("there is no valid clue for the action at index " +
+				strconv.Itoa(clue.ActionIndex))
+		} else {
+			action.Type = v.Type + 2 // Remap the clue type to the action type
+			action.Value = v.Value
+		}
+	}
+
+	options := def.GameRow.Options
+	return &GameJSON{ // nolint: exhaustivestruct
+		Players: def.Players,
+		Deck:    deck,
+		Actions: actions,
+		Options: &OptionsJSON{
+			StartingPlayer:        &options.StartingPlayer,
+			Variant:               &options.VariantName,
+			Timed:                 &options.Timed,
+			TimeBase:              &options.TimeBase,
+			TimePerTurn:           &options.TimePerTurn,
+			Speedrun:              &options.Speedrun,
+			TurnTimeLimit:         &options.TurnTimeLimit,
+			CardCycle:             &options.CardCycle,
+			DeckPlays:             &options.DeckPlays,
+			EmptyClues:            &options.EmptyClues,
+			OneExtraCard:          &options.OneExtraCard,
+			OneLessCard:           &options.OneLessCard,
+			AllOrNothing:          &options.AllOrNothing,
+			DetrimentalCharacters: &options.DetrimentalCharacters,
+		},
+	}, nil
+}
+
+func getGameStateClue(variant *Variant, deck []*CardIdentity, hand []int) (Clue, bool) {
+	candidates := make([]Clue, 0)
+	for _, rank := range variant.ClueRanks {
+		candidates = append(candidates, Clue{
+			Type:  ClueTypeRank,
+			Value: rank,
+		})
+	}
+	for i := range variant.ClueColors {
+		candidates = append(candidates, Clue{
+			Type:  ClueTypeColor,
+			Value: i,
+		})
+	}
+
+	for _, clue := range candidates {
+		for _, order := range hand {
+			card := &Card{ // nolint: exhaustivestruct
+				Order:     order,
+				SuitIndex: deck[order].SuitIndex,
+				Rank:      deck[order].Rank,
+			}
+			if variantIsCardTouched(variant.Name, clue, card) {
+				return clue, true
+			}
+		}
+	}
+
+	return Clue{}, false // nolint: exhaustivestruct
+}
+
+// emulateGameState plays through the game from the "getGameStateJSON()" function and checks that
+// it actually results in the state from the definition
+// The caller must not hold the tables lock
+func emulateGameState(def GameStateDefinition, gameJSON *GameJSON) (GameRow, error) {
+	ctx := NewMiscContext("emulateGameState")
+
+	tables.Lock(ctx)
+	defer tables.Unlock(ctx)
+
+	t := NewTable("Custom game state", -1)
+	t.Lock(ctx)
+	defer t.Unlock(ctx)
+	t.Visible = false
+
+	loadJSONOptionsToTable(&CommandData{ // nolint: exhaustivestruct
+		GameJSON: gameJSON,
+	}, t)
+	loadFakePlayers(t, gameJSON.Players)
+	tables.Set(t.ID, t)
+	defer deleteTable(t)
+
+	commandTableStart(ctx, t.Players[0].Session, &CommandData{ // nolint: exhaustivestruct
+		TableID:      t.ID,
+		NoTableLock:  true,
+		NoTablesLock: true,
+	})
+	g := t.Game
+	if g == nil {
+		return GameRow{}, errors.New("failed to start the game") // nolint: exhaustivestruct
+	}
+
+	if err := validateGameState(def, g); err != nil {
+		return GameRow{}, err // nolint: exhaustivestruct
+	}
+
+	return GameRow{
+		Name:             def.GameRow.Name,
+		Options:          def.GameRow.Options,
+		Seed:             "", // The seed is derived from the deck when the game is inserted
+		Score:            g.Score,
+		NumTurns:         g.Turn,
+		NumStrikes:       g.Strikes,
+		EndCondition:     g.EndCondition,
+		DatetimeStarted:  g.DatetimeStarted,
+		DatetimeFinished: time.Now(),
+	}, nil
+}
+
+func validateGameState(def GameStateDefinition, g *Game) error {
+	if g.InvalidActionOccurred {
+		return errors.New("one of the generated actions was not valid")
+	}
+	if g.EndCondition != EndConditionInProgress {
+		return errors.New("the game ended before the state was reached")
+	}
+	if g.ClueTokens != def.ClueTokens {
+		return errors.New("the game has " + strconv.Itoa(g.ClueTokens) + " clue tokens " +
+			"instead of " + strconv.Itoa(def.ClueTokens))
+	}
+	if g.Strikes != def.Strikes {
+		return errors.New("the game has " + strconv.Itoa(g.Strikes) + " strikes " +
+			"instead of " + strconv.Itoa(def.Strikes))
+	}
+	for i, stack := range g.Stacks {
+		if stack != def.Stacks[i] {
+			return errors.New("the stack at index " + strconv.Itoa(i) + " does not match")
+		}
+	}
+	for i, p := range g.Players {
+		if len(p.Hand) != len(def.Hands[i]) {
+			return errors.New("the hand of player " + strconv.Itoa(i) + " does not match")
+		}
+		for j, c := range p.Hand {
+			card := def.Hands[i][j]
+			if c.SuitIndex != card.SuitIndex || c.Rank != card.Rank {
+				return errors.New("the hand of player " + strconv.Itoa(i) + " does not match")
+			}
+		}
+	}
+
+	return nil
+}
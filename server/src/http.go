@@ -197,6 +197,7 @@ func httpInit() {
 	httpRouter.GET("/shared-replay/:databaseID/:turnID", httpMain) // Deprecated; needed for older links to work
 	httpRouter.GET("/replay-json/:string", httpMain)
 	httpRouter.GET("/shared-replay-json/:string", httpMain)
+	httpRouter.GET("/bookmark/:bookmarkID", httpBookmark)
 	httpRouter.GET("/create-table", httpMain)
 
 	// Path handlers for other URLs
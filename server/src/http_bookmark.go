@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// httpBookmark resolves a replay bookmark link into the replay of the game at the bookmarked turn
+func httpBookmark(c *gin.Context) {
+	// Local variables
+	w := c.Writer
+
+	// Parse the bookmark ID from the URL
+	bookmarkIDString := c.Param("bookmarkID")
+	if bookmarkIDString == "" {
+		http.Error(w, "Error: You must specify a bookmark ID.", http.StatusNotFound)
+		return
+	}
+
+	// Validate that it is a number
+	var bookmarkID int
+	if v, err := strconv.Atoi(bookmarkIDString); err != nil {
+		http.Error(w, "Error: That is not a valid bookmark ID.", http.StatusBadRequest)
+		return
+	} else {
+		bookmarkID = v
+	}
+
+	var bookmark *ReplayBookmark
+	if exists, v, err := models.ReplayBookmarks.Get(bookmarkID); err != nil {
+		logger.Error("Failed to get bookmark " + strconv.Itoa(bookmarkID) + ": " + err.Error())
+		http.Error(
+			w,
+			http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError,
+		)
+		return
+	} else if !exists {
+		http.Error(w, "Error: That bookmark does not exist in the database.", http.StatusNotFound)
+		return
+	} else {
+		bookmark = v
+	}
+
+	// Bookmarks are validated when they are created, but we check again so that a bad link results
+	// in a clear error instead of a replay that is stuck on the first turn
+	if valid, message := isReplayBookmarkTurnValid(bookmark.GameID, bookmark.Turn); !valid {
+		http.Error(w, "Error: "+message, http.StatusBadRequest)
+		return
+	}
+
+	// The client uses 1-indexed turns in the URL hash
+	path := "/replay/" + strconv.Itoa(bookmark.GameID) + "#" + strconv.Itoa(bookmark.Turn+1)
+	c.Redirect(http.StatusFound, path)
+}
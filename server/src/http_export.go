@@ -1,6 +1,7 @@
 package main
 
 import (
+	"math"
 	"net/http"
 	"strconv"
 
@@ -110,19 +111,88 @@ func httpExport(c *gin.Context) {
 		seed = v
 	}
 
+	// Games that were imported from JSON have their deck stored in the database
+	var deck []*CardIdentity
+	if v, err := models.GameDecks.Get(databaseID); err != nil {
+		logger.Error("Failed to get the deck for game " +
+			"\"" + strconv.Itoa(databaseID) + "\": " + err.Error())
+		http.Error(
+			w,
+			http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError,
+		)
+		return
+	} else if len(v) > 0 {
+		deck = v
+		// The seed of an imported game cannot be used to reconstruct the deck,
+		// so we omit it from the JSON so that the deck is used if the game is imported again
+		seed = ""
+	}
+
 	// Make a deck and shuffle it
 	g := &Game{ // nolint: exhaustivestruct
-		Options:      options,
-		ExtraOptions: &ExtraOptions{},
-		Seed:         seed,
+		Options: options,
+		ExtraOptions: &ExtraOptions{ // nolint: exhaustivestruct
+			CustomDeck: deck,
+		},
+		Seed: seed,
 	}
 	g.InitDeck()
-	setSeed(g.Seed) // Seed the random number generator
-	g.ShuffleDeck()
+	if deck == nil {
+		g.ShuffleDeck()
+	}
+
+	// Shared replays that start on a later turn only need the actions up to that turn
+	// (by default, all of the actions are exported)
+	turn := math.MaxInt32
+	turnSpecified := false
+	if turnString := c.Query("turn"); turnString != "" {
+		if v, err := strconv.Atoi(turnString); err != nil || v < 0 {
+			http.Error(w, "Error: That is not a valid turn.", http.StatusBadRequest)
+			return
+		} else {
+			turn = v
+			turnSpecified = true
+		}
+
+		// Every turn has at least one action, so the game cannot have more turns than actions
+		if numActions, err := models.GameActions.GetActionCount(databaseID); err != nil {
+			logger.Error("Failed to get the number of actions for game " +
+				strconv.Itoa(databaseID) + ": " + err.Error())
+			http.Error(
+				w,
+				http.StatusText(http.StatusInternalServerError),
+				http.StatusInternalServerError,
+			)
+			return
+		} else if turn > numActions {
+			http.Error(w, "Error: That game does not have that many turns.", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Some characters can take two actions per turn,
+	// so we have to emulate the game to find where the turn boundaries are
+	var actionTurns []int
+	if turnSpecified && options.DetrimentalCharacters {
+		ctx := NewMiscContext("httpExport")
+		if v, err := getGameActionTurns(ctx, databaseID); err != nil {
+			logger.Error("Failed to get the turns of the actions for game " +
+				strconv.Itoa(databaseID) + ": " + err.Error())
+			http.Error(
+				w,
+				http.StatusText(http.StatusInternalServerError),
+				http.StatusInternalServerError,
+			)
+			return
+		} else {
+			actionTurns = v
+		}
+	}
 
 	// Get the actions from the database
 	var actions []*GameAction
-	if v, err := models.GameActions.GetAll(databaseID); err != nil {
+	if v, err := models.GameActions.GetUpToTurn(databaseID, turn, actionTurns); err != nil {
 		logger.Error("Failed to get the actions from the database for game " +
 			strconv.Itoa(databaseID) + ": " + err.Error())
 		http.Error(
@@ -197,6 +267,10 @@ func httpExport(c *gin.Context) {
 		optionsJSON.Speedrun = &options.Speedrun
 		allDefaultOptions = false
 	}
+	if options.TurnTimeLimit {
+		optionsJSON.TurnTimeLimit = &options.TurnTimeLimit
+		allDefaultOptions = false
+	}
 	if options.CardCycle {
 		optionsJSON.CardCycle = &options.CardCycle
 		allDefaultOptions = false
@@ -38,6 +38,11 @@ func httpLocalhostBan(c *gin.Context, username string, ip string, userID int) {
 		return
 	}
 
+	// Bans from localhost are performed by the server administrator, so there is no moderator ID
+	if err := models.ModLog.Insert(0, userID, ModLogActionBan, "Banned IP \""+ip+"\""); err != nil {
+		logger.Error("Failed to insert the moderator log entry for the ban: " + err.Error())
+	}
+
 	logoutUser(userID)
 
 	c.String(http.StatusOK, "success\n")
@@ -38,6 +38,11 @@ func httpLocalhostMute(c *gin.Context, username string, ip string, userID int) {
 		return
 	}
 
+	// Mutes from localhost are performed by the server administrator, so there is no moderator ID
+	if err := models.ModLog.Insert(0, userID, ModLogActionMute, "Muted IP \""+ip+"\""); err != nil {
+		logger.Error("Failed to insert the moderator log entry for the mute: " + err.Error())
+	}
+
 	// They need to re-login for the mute to take effect,
 	// so disconnect their existing connection, if any
 	logoutUser(userID)
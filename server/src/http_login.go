@@ -65,6 +65,17 @@ func httpLogin(c *gin.Context) {
 	}
 
 	if exists {
+		// Merged users are kept in the database only to reserve the username
+		if user.MergedInto.Valid {
+			http.Error(
+				w,
+				"This account has been merged into another account. "+
+					"Please log in with your other account.",
+				http.StatusUnauthorized,
+			)
+			return
+		}
+
 		// First, check to see if they have a a legacy password hash stored in the database
 		if user.OldPasswordHash.Valid {
 			if ok := httpCheckAndChangeCredentials(w, user, data); !ok {
@@ -90,6 +90,19 @@ func httpWS(c *gin.Context) {
 		username = v
 	}
 
+	// The user might have been merged into another user after they logged in
+	if merged, err := models.Users.IsMerged(userID); err != nil {
+		msg := "Failed to check to see if user " + strconv.Itoa(userID) + " was merged: " +
+			err.Error()
+		httpWSError(c, msg)
+		return
+	} else if merged {
+		msg := "User \"" + username + "\" tried to login with a cookie for a merged user. " +
+			"Deleting their cookie."
+		httpWSDeny(c, msg)
+		return
+	}
+
 	// Validation was successful; update the database with "datetime_last_login" and "last_ip"
 	if err := models.Users.Update(userID, ip); err != nil {
 		msg := "Failed to set \"datetime_last_login\" and \"last_ip\" for user " +
@@ -193,6 +193,19 @@ func main() {
 	// Restore tables that were ongoing at the time of the last server restart
 	restoreTables()
 
+	// Restore tables that were ongoing at the time of the last server crash (in "checkpoints.go")
+	restoreCheckpoints()
+
+	// Start periodically saving ongoing games so that they can be restored after a crash
+	// (in "checkpoints.go")
+	go checkpointsSweep()
+
+	// Start periodically pruning old rate limit entries (in "rate_limits.go")
+	go rateLimitsPruneSweep()
+
+	// Start terminating games that every player has left (in "abandoned_games.go")
+	abandonedGamesInit()
+
 	// Specify that we are running the HTTP framework in production
 	// (it is "gin.DebugMode" by default)
 	// Comment this out to debug HTTP stuff
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+// TestMain loads the game data from the JSON files (in the same way that the "main()" function
+// does), since many of the functions that are tested depend on the variant definitions
+func TestMain(m *testing.M) {
+	jsonPath = path.Join("..", "..", "packages", "game", "src", "json")
+
+	colorsInit()   // (in "colors.go")
+	suitsInit()    // (in "suits.go")
+	variantsInit() // (in "variants.go")
+
+	os.Exit(m.Run())
+}
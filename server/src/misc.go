@@ -121,6 +121,18 @@ func intInSlice(a int, slice []int) bool {
 	return false
 }
 
+func intSlicesEqual(a []int, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // isValidUrl tests a string to determine if it is a well-structured url or not
 // From: https://golangcode.com/how-to-check-if-a-string-is-a-url/
 func isValidURL(toTest string) bool {
@@ -297,11 +309,11 @@ func secondsToDurationString(seconds int) (string, error) {
 	return msg, nil
 }
 
-// setSeed seeds the random number generator with a string
-// Golang's "rand.Seed()" function takes an int64, so we need to convert a string to an int64
+// getSeedInt64 converts a seed string to a number
+// Golang's "rand.NewSource()" function takes an int64, so we need to convert a string to an int64
 // We use the CRC64 hash function to do this
 // Also note that seeding with negative numbers will not work
-func setSeed(seed string) {
+func getSeedInt64(seed string) int64 {
 	// Remove the "legacy-x-" prefix from the seed, if it exists
 	// (e.g. "legacy-1-", "legacy-2-", and so on)
 	if strings.HasPrefix(seed, "legacy-") {
@@ -309,7 +321,13 @@ func setSeed(seed string) {
 	}
 	crc64Table := crc64.MakeTable(crc64.ECMA)
 	intSeed := crc64.Checksum([]byte(seed), crc64Table)
-	rand.Seed(int64(intSeed))
+	return int64(intSeed)
+}
+
+// getSeedPrefix returns the part of a seed that is based on the game options
+// e.g. "p2v0s" for a 2-player no variant game
+func getSeedPrefix(numPlayers int, variantID int) string {
+	return "p" + strconv.Itoa(numPlayers) + "v" + strconv.Itoa(variantID) + "s"
 }
 
 func stringInSlice(a string, slice []string) bool {
@@ -400,6 +418,11 @@ func fixGameOptions(options *Options) *Options {
 		options.TimePerTurn = 0
 	}
 
+	// Validate that only speedruns can have a turn time limit
+	if !options.Speedrun {
+		options.TurnTimeLimit = false
+	}
+
 	// Validate that they did not send both the "One Extra Card" and the "One Less Card" option at
 	// the same time (they effectively cancel each other out)
 	if options.OneExtraCard && options.OneLessCard {
@@ -407,6 +430,20 @@ func fixGameOptions(options *Options) *Options {
 		options.OneLessCard = false
 	}
 
+	// Validate that there can be no pool of allowed characters if this is not a game with
+	// detrimental characters, and remove any duplicate characters from the pool
+	if !options.DetrimentalCharacters || len(options.AllowedCharacters) == 0 {
+		options.AllowedCharacters = nil
+	} else {
+		allowedCharacters := make([]int, 0, len(options.AllowedCharacters))
+		for _, characterID := range options.AllowedCharacters {
+			if !intInSlice(characterID, allowedCharacters) {
+				allowedCharacters = append(allowedCharacters, characterID)
+			}
+		}
+		options.AllowedCharacters = allowedCharacters
+	}
+
 	return options
 }
 
@@ -442,6 +479,39 @@ func areGameOptionsValid(options *Options) (bool, string) {
 		}
 	}
 
+	// Validate that every character in the pool exists
+	for _, characterID := range options.AllowedCharacters {
+		if _, ok := characterIDMap[characterID]; !ok {
+			msg := "\"" + strconv.Itoa(characterID) + "\" is not a valid character ID."
+			return false, msg
+		}
+	}
+
+	return true, ""
+}
+
+// Checks that there are enough characters in the pool of allowed characters to give each player a
+// different one
+func isCharacterPoolValid(options *Options, numPlayers int) (bool, string) {
+	if !options.DetrimentalCharacters || len(options.AllowedCharacters) == 0 {
+		return true, ""
+	}
+
+	numCharacters := 0
+	for _, characterName := range getCharacterPool(options) {
+		if characters[characterName].Not2P && numPlayers == 2 {
+			continue
+		}
+		numCharacters++
+	}
+
+	if numCharacters < numPlayers {
+		msg := "The pool of allowed characters only has " + strconv.Itoa(numCharacters) +
+			" character(s) that can be used with " + strconv.Itoa(numPlayers) + " players, " +
+			"but each player must be dealt a different character."
+		return false, msg
+	}
+
 	return true, ""
 }
 
@@ -491,6 +561,13 @@ func isTableCommandValid(s *Session, d *CommandData, data *SpecialGameData) (boo
 		// and so on
 		// However, the seed does not actually have to be a number,
 		// so allow the user to use any arbitrary string as a seed suffix
+		// (as long as it cannot be confused with a seed that the server generated)
+		if seedIsGenerated(args[0]) && !d.AllowGeneratedSeed {
+			msg := "Seed names must contain at least one letter or <code>-</code>, " +
+				"since numeric seeds are reserved for the server."
+			return false, msg
+		}
+
 		data.SetSeedSuffix = args[0]
 	} else if command == "replay" {
 		// !replay - Replay a specific game up to a specific turn
@@ -562,3 +639,25 @@ func isTableCommandValid(s *Session, d *CommandData, data *SpecialGameData) (boo
 
 	return true, ""
 }
+
+// isReplayBookmarkTurnValid checks that a bookmark turn is within the range of a database game
+// The final turn is the one after the last action has been performed
+func isReplayBookmarkTurnValid(databaseID int, turn int) (bool, string) {
+	var numTurns int
+	if v, err := models.Games.GetNumTurns(databaseID); err != nil {
+		logger.Error("Failed to get the number of turns for game " + strconv.Itoa(databaseID) +
+			": " + err.Error())
+		return false, DefaultErrorMsg
+	} else {
+		numTurns = v
+	}
+
+	if turn < 0 || turn > numTurns {
+		msg := "Turn " + strconv.Itoa(turn) + " is out of range for game #" +
+			strconv.Itoa(databaseID) + ", which only has turns 0 through " +
+			strconv.Itoa(numTurns) + "."
+		return false, msg
+	}
+
+	return true, ""
+}
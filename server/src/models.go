@@ -8,6 +8,8 @@ import (
 	"strings"
 
 	"github.com/Hanabi-Live/hanabi-live/logger"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
@@ -16,23 +18,39 @@ var (
 	dbName string
 )
 
+// dbQuerier is satisfied by both the connection pool and by a transaction,
+// so that the same insert can be performed by itself or as one part of a larger transaction
+type dbQuerier interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
 // Models contains a list of interfaces representing database tables
 type Models struct {
 	BannedIPs
+	Campaigns
 	ChatLog
 	ChatLogPM
 	DiscordWaiters
 	GameActions
+	GameDecks
+	GameEvents
 	GameParticipantNotes
 	GameParticipants
 	Games
 	GameTags
+	Hypotheticals
 	Metadata
+	ModLog
 	MutedIPs
+	RateLimits
+	ReplayBookmarks
 	Seeds
 	Users
 	UserFriends
 	UserLinkages
+	UserProfiles
+	UserRatings
 	UserReverseFriends
 	UserSettings
 	UserStats
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Campaigns are a series of linked games that are played by a named team,
+// where the scores carry forward from game to game
+type Campaigns struct{}
+
+type CampaignProgress struct {
+	ID          int             `json:"id"`
+	Name        string          `json:"name"`
+	MemberNames []string        `json:"memberNames"`
+	Games       []*CampaignGame `json:"games"` // In the order that they were played
+	TotalScore  int             `json:"totalScore"`
+}
+
+type CampaignGame struct {
+	GameID           int       `json:"gameID"`
+	VariantID        int       `json:"variantID"`
+	Score            int       `json:"score"`
+	CumulativeScore  int       `json:"cumulativeScore"`
+	DatetimeFinished time.Time `json:"datetimeFinished"`
+}
+
+// Create makes a new campaign and returns its ID
+func (*Campaigns) Create(name string, memberIDs []int) (int, error) {
+	if len(memberIDs) == 0 {
+		return -1, errors.New("a campaign must have at least one member")
+	}
+
+	var tx pgx.Tx
+	if v, err := db.Begin(context.Background()); err != nil {
+		return -1, err
+	} else {
+		tx = v
+	}
+	defer tx.Rollback(context.Background()) // nolint: errcheck
+
+	var campaignID int
+	if err := tx.QueryRow(context.Background(), `
+		INSERT INTO campaigns (name)
+		VALUES ($1)
+		RETURNING id
+	`, name).Scan(&campaignID); err != nil {
+		return -1, err
+	}
+
+	// Ignore duplicate members (instead of failing on the primary key)
+	memberIDMap := make(map[int]struct{})
+	SQLString := `
+		INSERT INTO campaign_members (campaign_id, user_id)
+		VALUES %s
+	`
+	numArgsPerRow := 2
+	valueArgs := make([]interface{}, 0, numArgsPerRow*len(memberIDs))
+	for _, memberID := range memberIDs {
+		if _, ok := memberIDMap[memberID]; ok {
+			continue
+		}
+		memberIDMap[memberID] = struct{}{}
+		valueArgs = append(valueArgs, campaignID, memberID)
+	}
+	SQLString = getBulkInsertSQLSimple(SQLString, numArgsPerRow, len(memberIDMap))
+
+	if _, err := tx.Exec(context.Background(), SQLString, valueArgs...); err != nil {
+		return -1, err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		return -1, err
+	}
+
+	return campaignID, nil
+}
+
+// AddGame links a game to a campaign
+// It returns an error if the game is already linked to a campaign (including this one)
+// (a game can only be in one campaign, since "game_id" is the primary key)
+func (*Campaigns) AddGame(campaignID int, gameID int) error {
+	if tag, err := db.Exec(context.Background(), `
+		INSERT INTO campaign_games (game_id, campaign_id)
+		VALUES ($1, $2)
+		ON CONFLICT (game_id) DO NOTHING
+	`, gameID, campaignID); err != nil {
+		return err
+	} else if tag.RowsAffected() == 0 {
+		return errors.New("game " + strconv.Itoa(gameID) + " is already linked to a campaign")
+	}
+
+	return nil
+}
+
+// GetProgress returns the games of a campaign in the order that they were played,
+// along with the running total of the scores
+func (*Campaigns) GetProgress(campaignID int) (*CampaignProgress, error) {
+	progress := &CampaignProgress{ // nolint: exhaustivestruct
+		ID:          campaignID,
+		MemberNames: make([]string, 0),
+		Games:       make([]*CampaignGame, 0),
+	}
+
+	if err := db.QueryRow(context.Background(), `
+		SELECT name
+		FROM campaigns
+		WHERE id = $1
+	`, campaignID).Scan(&progress.Name); errors.Is(err, pgx.ErrNoRows) {
+		return progress, errors.New("campaign " + strconv.Itoa(campaignID) + " does not exist")
+	} else if err != nil {
+		return progress, err
+	}
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT users.username
+		FROM campaign_members
+			JOIN users ON campaign_members.user_id = users.id
+		WHERE campaign_members.campaign_id = $1
+		ORDER BY LOWER(users.username)
+	`, campaignID); err != nil {
+		return progress, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var memberName string
+		if err := rows.Scan(&memberName); err != nil {
+			return progress, err
+		}
+		progress.MemberNames = append(progress.MemberNames, memberName)
+	}
+
+	if err := rows.Err(); err != nil {
+		return progress, err
+	}
+	rows.Close()
+
+	if v, err := db.Query(context.Background(), `
+		SELECT
+			games.id,
+			games.variant_id,
+			games.score,
+			games.datetime_finished
+		FROM campaign_games
+			JOIN games ON campaign_games.game_id = games.id
+		WHERE campaign_games.campaign_id = $1
+			AND games.datetime_deleted IS NULL
+		ORDER BY games.datetime_started, games.id
+	`, campaignID); err != nil {
+		return progress, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var game CampaignGame
+		if err := rows.Scan(
+			&game.GameID,
+			&game.VariantID,
+			&game.Score,
+			&game.DatetimeFinished,
+		); err != nil {
+			return progress, err
+		}
+		progress.TotalScore += game.Score
+		game.CumulativeScore = progress.TotalScore
+		progress.Games = append(progress.Games, &game)
+	}
+
+	if err := rows.Err(); err != nil {
+		return progress, err
+	}
+	rows.Close()
+
+	return progress, nil
+}
@@ -13,9 +13,10 @@ type ChatLog struct{}
 
 // ChatLogRow mirrors the "chat_log" table row
 type ChatLogRow struct {
-	UserID  int
-	Message string
-	Room    string
+	UserID   int
+	Message  string
+	Room     string
+	Datetime time.Time
 }
 
 func (*ChatLog) Insert(userID int, message string, room string) error {
@@ -28,13 +29,19 @@ func (*ChatLog) Insert(userID int, message string, room string) error {
 
 func (*ChatLog) BulkInsert(chatLogRows []*ChatLogRow) error {
 	SQLString := `
-		INSERT INTO chat_log (user_id, message, room)
+		INSERT INTO chat_log (user_id, message, room, datetime_sent)
 		VALUES %s
 	`
-	numArgsPerRow := 3
+	numArgsPerRow := 4
 	valueArgs := make([]interface{}, 0, numArgsPerRow*len(chatLogRows))
 	for _, chatLogRow := range chatLogRows {
-		valueArgs = append(valueArgs, chatLogRow.UserID, chatLogRow.Message, chatLogRow.Room)
+		valueArgs = append(
+			valueArgs,
+			chatLogRow.UserID,
+			chatLogRow.Message,
+			chatLogRow.Room,
+			chatLogRow.Datetime,
+		)
 	}
 	SQLString = getBulkInsertSQLSimple(SQLString, numArgsPerRow, len(chatLogRows))
 
@@ -109,3 +116,79 @@ func (*ChatLog) Get(room string, count int) ([]DBChatMessage, error) {
 
 	return chatMessages, nil
 }
+
+// ReassignTable moves the chat from the room of a table to the room of the game that was played at
+// that table
+// The server now writes the chat of a table directly to the room of the game when the game ends
+// (in the "WriteDatabase()" function), so this is only needed for the rows that older versions of
+// the server wrote to the room of the table
+// Table IDs are reused after a server restart,
+// so we only move rows that were written after the game finished
+func (*ChatLog) ReassignTable(tableID int, gameID int) error {
+	_, err := db.Exec(context.Background(), `
+		UPDATE chat_log
+		SET room = $1
+		WHERE room = $2
+			AND datetime_sent >= (
+				SELECT datetime_finished
+				FROM games
+				WHERE id = $3
+			)
+	`, getGameRoomName(gameID), "table"+strconv.Itoa(tableID), gameID)
+	return err
+}
+
+// GetByGame returns the chat from the table that a game was played at, in the order that it was
+// sent
+// The chat is written to the game room when the game ends (in the "WriteDatabase()" function),
+// so that it can be retrieved later on in a replay
+// Private messages are stored in the "chat_log_pm" table, so they are never included
+func (*ChatLog) GetByGame(gameID int) ([]DBChatMessage, error) {
+	chatMessages := make([]DBChatMessage, 0)
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT
+			COALESCE(users.username, '__server'),
+			chat_log.discord_name,
+			chat_log.message,
+			chat_log.datetime_sent
+		FROM
+			chat_log
+		LEFT JOIN
+			users ON users.id = chat_log.user_id
+		WHERE
+			room = $1
+		ORDER BY
+			chat_log.datetime_sent,
+			chat_log.id
+	`, getGameRoomName(gameID)); err != nil {
+		return chatMessages, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var message DBChatMessage
+		if err := rows.Scan(
+			&message.Name,
+			&message.DiscordName,
+			&message.Message,
+			&message.Datetime,
+		); err != nil {
+			return chatMessages, err
+		}
+		chatMessages = append(chatMessages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return chatMessages, err
+	}
+	rows.Close()
+
+	return chatMessages, nil
+}
+
+func getGameRoomName(gameID int) string {
+	return "game" + strconv.Itoa(gameID)
+}
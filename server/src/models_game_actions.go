@@ -2,6 +2,9 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"strconv"
 
 	"github.com/jackc/pgx/v4"
 )
@@ -22,20 +25,27 @@ type GameActionRow struct {
 	Type   int
 	Target int
 	Value  int
+	// In milliseconds; null for "game over" actions and for games without timing information
+	ThinkTime sql.NullInt32
 }
 
 func (*GameActions) BulkInsert(gameActionRows []*GameActionRow) error {
+	return gameActionsBulkInsert(db, gameActionRows)
+}
+
+func gameActionsBulkInsert(q dbQuerier, gameActionRows []*GameActionRow) error {
 	SQLString := `
 		INSERT INTO game_actions (
 			game_id,
 			turn,
 			type,
 			target,
-			value
+			value,
+			think_time
 		)
 		VALUES %s
 	`
-	numArgsPerRow := 5
+	numArgsPerRow := 6
 	valueArgs := make([]interface{}, 0, numArgsPerRow*len(gameActionRows))
 	for _, gameActionRow := range gameActionRows {
 		valueArgs = append(
@@ -45,14 +55,63 @@ func (*GameActions) BulkInsert(gameActionRows []*GameActionRow) error {
 			gameActionRow.Type,
 			gameActionRow.Target,
 			gameActionRow.Value,
+			gameActionRow.ThinkTime,
 		)
 	}
 	SQLString = getBulkInsertSQLSimple(SQLString, numArgsPerRow, len(gameActionRows))
 
-	_, err := db.Exec(context.Background(), SQLString, valueArgs...)
+	_, err := q.Exec(context.Background(), SQLString, valueArgs...)
 	return err
 }
 
+// Append inserts a single action at the end of the action log of a game
+// The "turn" column is really the index of the action (see the "getGameActionTurns()" function),
+// so it must be equal to the number of actions that are already stored for the game
+// The row for the game is locked so that two concurrent appends cannot both pass this check
+func (*GameActions) Append(gameID int, action GameAction, turn int) error {
+	var tx pgx.Tx
+	if v, err := db.Begin(context.Background()); err != nil {
+		return err
+	} else {
+		tx = v
+	}
+	defer tx.Rollback(context.Background()) // nolint: errcheck
+
+	if tag, err := tx.Exec(context.Background(), `
+		SELECT id
+		FROM games
+		WHERE id = $1
+		FOR UPDATE
+	`, gameID); err != nil {
+		return err
+	} else if tag.RowsAffected() == 0 {
+		return errors.New("game " + strconv.Itoa(gameID) + " does not exist")
+	}
+
+	var numActions int
+	if err := tx.QueryRow(context.Background(), `
+		SELECT COUNT(*)
+		FROM game_actions
+		WHERE game_id = $1
+	`, gameID).Scan(&numActions); err != nil {
+		return err
+	}
+	if turn != numActions {
+		return errors.New("failed to append an action at index " + strconv.Itoa(turn) + " " +
+			"of game " + strconv.Itoa(gameID) + ", " +
+			"since it has " + strconv.Itoa(numActions) + " actions")
+	}
+
+	if _, err := tx.Exec(context.Background(), `
+		INSERT INTO game_actions (game_id, turn, type, target, value)
+		VALUES ($1, $2, $3, $4, $5)
+	`, gameID, turn, action.Type, action.Target, action.Value); err != nil {
+		return err
+	}
+
+	return tx.Commit(context.Background())
+}
+
 func (*GameActions) GetAll(databaseID int) ([]*GameAction, error) {
 	actions := make([]*GameAction, 0)
 
@@ -92,3 +151,102 @@ func (*GameActions) GetAll(databaseID int) ([]*GameAction, error) {
 
 	return actions, nil
 }
+
+// GetUpToTurn returns the actions that were performed before the game reached the given turn
+// (which uses the same numbering as "g.Turn")
+// This is used to send a smaller payload for shared replays that start on a later turn
+// "actionTurns" is the in-game turn of each stored action (from the "getGameActionTurns()"
+// function); it can be nil if every action corresponds to one turn
+// (e.g. when the "Detrimental Characters" option is turned off)
+func (ga *GameActions) GetUpToTurn(
+	databaseID int,
+	turn int,
+	actionTurns []int,
+) ([]*GameAction, error) {
+	var actions []*GameAction
+	if v, err := ga.GetAll(databaseID); err != nil {
+		return nil, err
+	} else {
+		actions = v
+	}
+
+	if turn <= 0 {
+		return make([]*GameAction, 0), nil
+	}
+
+	if actionTurns == nil {
+		if turn > len(actions) {
+			turn = len(actions)
+		}
+		return actions[:turn], nil
+	}
+
+	numActions := 0
+	for _, actionTurn := range actionTurns {
+		if actionTurn >= turn || numActions >= len(actions) {
+			break
+		}
+		numActions++
+	}
+
+	return actions[:numActions], nil
+}
+
+// GetActionCount returns the number of actions that are stored for a game
+// Since every turn has at least one action, this is also the upper bound for the number of turns
+func (*GameActions) GetActionCount(databaseID int) (int, error) {
+	var count int
+	err := db.QueryRow(context.Background(), `
+		SELECT COUNT(*)
+		FROM game_actions
+		WHERE game_id = $1
+	`, databaseID).Scan(&count)
+	return count, err
+}
+
+type TurnTiming struct {
+	// This is the index of the action, which corresponds to the "turn" column
+	Turn int `json:"turn"`
+	// In milliseconds; time spent while the game was paused is not included
+	ThinkTime int `json:"thinkTime"`
+}
+
+// GetTurnTimings returns the time that was spent on every action of a game
+// Actions without timing information (e.g. "game over" actions) are not included
+func (*GameActions) GetTurnTimings(databaseID int) ([]TurnTiming, error) {
+	timings := make([]TurnTiming, 0)
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT
+			turn,
+			think_time
+		FROM game_actions
+		WHERE game_id = $1
+			AND think_time IS NOT NULL
+		ORDER BY turn
+	`, databaseID); err != nil {
+		return timings, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var timing TurnTiming
+		if err := rows.Scan(
+			&timing.Turn,
+			&timing.ThinkTime,
+		); err != nil {
+			return timings, err
+		}
+
+		timings = append(timings, timing)
+	}
+
+	if err := rows.Err(); err != nil {
+		return timings, err
+	}
+	rows.Close()
+
+	return timings, nil
+}
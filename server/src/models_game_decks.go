@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type GameDecks struct{}
+
+// GameDeckRow mirrors the "game_decks" table row
+type GameDeckRow struct {
+	GameID    int
+	CardOrder int
+	SuitIndex int
+	Rank      int
+}
+
+func (*GameDecks) BulkInsert(gameDeckRows []*GameDeckRow) error {
+	return gameDecksBulkInsert(db, gameDeckRows)
+}
+
+func gameDecksBulkInsert(q dbQuerier, gameDeckRows []*GameDeckRow) error {
+	SQLString := `
+		INSERT INTO game_decks (
+			game_id,
+			card_order,
+			suit_index,
+			rank
+		)
+		VALUES %s
+	`
+	numArgsPerRow := 4
+	valueArgs := make([]interface{}, 0, numArgsPerRow*len(gameDeckRows))
+	for _, gameDeckRow := range gameDeckRows {
+		valueArgs = append(
+			valueArgs,
+			gameDeckRow.GameID,
+			gameDeckRow.CardOrder,
+			gameDeckRow.SuitIndex,
+			gameDeckRow.Rank,
+		)
+	}
+	SQLString = getBulkInsertSQLSimple(SQLString, numArgsPerRow, len(gameDeckRows))
+
+	_, err := q.Exec(context.Background(), SQLString, valueArgs...)
+	return err
+}
+
+// Get returns the stored deck for a game
+// Most games do not have a stored deck (since it can be derived from the seed),
+// in which case the slice will be empty
+func (*GameDecks) Get(databaseID int) ([]*CardIdentity, error) {
+	deck := make([]*CardIdentity, 0)
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT
+			suit_index,
+			rank
+		FROM game_decks
+		WHERE game_id = $1
+		ORDER BY card_order
+	`, databaseID); err != nil {
+		return deck, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var card CardIdentity
+		if err := rows.Scan(
+			&card.SuitIndex,
+			&card.Rank,
+		); err != nil {
+			return deck, err
+		}
+
+		deck = append(deck, &card)
+	}
+
+	if err := rows.Err(); err != nil {
+		return deck, err
+	}
+	rows.Close()
+
+	return deck, nil
+}
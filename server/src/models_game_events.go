@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+)
+
+type GameEvents struct{}
+
+// GameEvent mirrors the "game_events" table row
+type GameEvent struct {
+	GameID    int
+	UserID    int
+	EventType string
+}
+
+// Insert records an event for a user
+// Inserting an event that was already recorded does nothing,
+// so that running the detection on the same game twice does not count the events twice
+func (*GameEvents) Insert(gameID int, userID int, eventType string) error {
+	_, err := db.Exec(context.Background(), `
+		INSERT INTO game_events (game_id, user_id, event_type)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (game_id, user_id, event_type) DO NOTHING
+	`, gameID, userID, eventType)
+	return err
+}
+
+// CountByUser returns how many times an event has happened to a user
+// Events from deleted games are not counted
+func (*GameEvents) CountByUser(userID int, eventType string) (int, error) {
+	var count int
+	err := db.QueryRow(context.Background(), `
+		SELECT COUNT(game_events.game_id)
+		FROM game_events
+			JOIN games ON game_events.game_id = games.id
+		WHERE game_events.user_id = $1
+			AND game_events.event_type = $2
+			AND games.datetime_deleted IS NULL
+	`, userID, eventType).Scan(&count)
+	return count, err
+}
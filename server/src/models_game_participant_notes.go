@@ -2,6 +2,10 @@ package main
 
 import (
 	"context"
+	"errors"
+	"strconv"
+
+	"github.com/jackc/pgx/v4"
 )
 
 type GameParticipantNotes struct{}
@@ -15,6 +19,13 @@ type GameParticipantNotesRow struct {
 }
 
 func (*GameParticipantNotes) BulkInsert(gameParticipantNotesRows []*GameParticipantNotesRow) error {
+	return gameParticipantNotesBulkInsert(db, gameParticipantNotesRows)
+}
+
+func gameParticipantNotesBulkInsert(
+	q dbQuerier,
+	gameParticipantNotesRows []*GameParticipantNotesRow,
+) error {
 	SQLString := `
 		INSERT INTO game_participant_notes (
 			game_participant_id,
@@ -41,6 +52,81 @@ func (*GameParticipantNotes) BulkInsert(gameParticipantNotesRows []*GameParticip
 	`
 	SQLString = getBulkInsertSQL(SQLString, valueSQL, len(gameParticipantNotesRows))
 
-	_, err := db.Exec(context.Background(), SQLString, valueArgs...)
+	_, err := q.Exec(context.Background(), SQLString, valueArgs...)
 	return err
 }
+
+// Upsert sets the note that a player wrote on a card in a game that has already ended
+// (e.g. when they are reviewing a replay)
+// An empty note removes the row so that there are no rows with blank notes;
+// this matches "BulkInsert()", which is only passed the notes that are not blank
+func (*GameParticipantNotes) Upsert(gameID int, userID int, cardOrder int, note string) error {
+	if note == "" {
+		_, err := db.Exec(context.Background(), `
+			DELETE FROM game_participant_notes
+			WHERE game_participant_id = (
+				SELECT id
+				FROM game_participants
+				WHERE game_id = $1
+					AND user_id = $2
+			)
+				AND card_order = $3
+		`, gameID, userID, cardOrder)
+		return err
+	}
+
+	if tag, err := db.Exec(context.Background(), `
+		INSERT INTO game_participant_notes (game_participant_id, card_order, note)
+		SELECT id, $3, $4
+		FROM game_participants
+		WHERE game_id = $1
+			AND user_id = $2
+		ON CONFLICT (game_participant_id, card_order) DO UPDATE
+		SET note = EXCLUDED.note
+	`, gameID, userID, cardOrder, note); err != nil {
+		return err
+	} else if tag.RowsAffected() == 0 {
+		return errors.New("user " + strconv.Itoa(userID) + " did not play in game " +
+			strconv.Itoa(gameID))
+	}
+
+	return nil
+}
+
+// GetByGameAndUser returns the notes that a player wrote in a game, indexed by card order
+// Cards without a note are not included
+func (*GameParticipantNotes) GetByGameAndUser(gameID int, userID int) (map[int]string, error) {
+	notes := make(map[int]string)
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT
+			game_participant_notes.card_order,
+			game_participant_notes.note
+		FROM game_participant_notes
+			JOIN game_participants
+				ON game_participant_notes.game_participant_id = game_participants.id
+		WHERE game_participants.game_id = $1
+			AND game_participants.user_id = $2
+	`, gameID, userID); err != nil {
+		return notes, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var cardOrder int
+		var note string
+		if err := rows.Scan(&cardOrder, &note); err != nil {
+			return notes, err
+		}
+		notes[cardOrder] = note
+	}
+
+	if err := rows.Err(); err != nil {
+		return notes, err
+	}
+	rows.Close()
+
+	return notes, nil
+}
@@ -2,44 +2,437 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgx/v4"
 )
 
 type GameParticipants struct{}
 
-// GameParticipantsRow mirrors the "game_participants" table row
+// GameParticipantsRow roughly mirrors the "game_participants" table row
 type GameParticipantsRow struct {
 	GameID              int
 	UserID              int
 	Seat                int
 	CharacterAssignment int
-	CharacterMetadata   int
+	CharacterMetadata   DBCharacterMetadata
+	// Null if the player was connected when the game ended
+	DatetimeDisconnected sql.NullTime
+
+	// Username is joined from the "users" table when reading rows; it is ignored when inserting
+	Username string
+}
+
+// DBCharacterMetadata is the representation of character metadata in the database
+// It is stored as a JSON object so that a character can keep track of more than one value
+type DBCharacterMetadata map[string]int
+
+const (
+	// Characters that only need a single value store it under this key
+	dbCharacterMetadataKeyValue = "value"
+
+	// Before character metadata was stored as JSON, it was stored as an integer of value + 1
+	// (where 0 represented null metadata); these values were migrated to this key
+	dbCharacterMetadataKeyLegacy = "legacy"
+)
+
+// NewDBCharacterMetadata converts the in-game metadata of a character to the database
+// representation
+// -1 is considered to be "null" metadata, which is represented by an empty object
+func NewDBCharacterMetadata(metadata int) DBCharacterMetadata {
+	characterMetadata := make(DBCharacterMetadata)
+	if metadata != -1 {
+		characterMetadata[dbCharacterMetadataKeyValue] = metadata
+	}
+	return characterMetadata
+}
+
+// Value returns the in-game metadata of a character, or -1 if the metadata is null
+func (m DBCharacterMetadata) Value() int {
+	if v, ok := m[dbCharacterMetadataKeyValue]; ok {
+		return v
+	}
+	if v, ok := m[dbCharacterMetadataKeyLegacy]; ok {
+		return v - 1
+	}
+	return -1
+}
+
+// parseDBCharacterMetadata unmarshals the "character_metadata" column
+// Rows that have not been migrated yet will contain a bare integer instead of an object,
+// so we also accept that format
+func parseDBCharacterMetadata(characterMetadataJSON []byte) (DBCharacterMetadata, error) {
+	characterMetadata := make(DBCharacterMetadata)
+	if err := json.Unmarshal(characterMetadataJSON, &characterMetadata); err == nil {
+		return characterMetadata, nil
+	}
+
+	var legacyMetadata int
+	if err := json.Unmarshal(characterMetadataJSON, &legacyMetadata); err != nil {
+		return make(DBCharacterMetadata), err
+	}
+
+	return DBCharacterMetadata{
+		dbCharacterMetadataKeyLegacy: legacyMetadata,
+	}, nil
+}
+
+// InvalidCharacterAssignmentError is returned when a participant row has a character assignment
+// that does not correspond to a character, a character assignment for a game without characters,
+// or a character that is not in the pool of allowed characters for the game
+type InvalidCharacterAssignmentError struct {
+	Seat                  int
+	CharacterAssignment   int
+	DetrimentalCharacters bool
+	NotAllowed            bool
 }
 
-func (*GameParticipants) BulkInsert(gameParticipantsRows []*GameParticipantsRow) error {
+func (e *InvalidCharacterAssignmentError) Error() string {
+	msg := "the character assignment of " + strconv.Itoa(e.CharacterAssignment) + " for seat " +
+		strconv.Itoa(e.Seat) + " is not valid"
+	if !e.DetrimentalCharacters {
+		msg += " for a game without detrimental characters"
+	} else if e.NotAllowed {
+		msg += " since it is not in the pool of allowed characters"
+	}
+	return msg
+}
+
+// validateCharacterAssignment checks a "character_assignment" value against the characters map
+// - Games without detrimental characters always store a character assignment of 0.
+// - Games with detrimental characters store the character ID, or -1 for "n/a".
+// - Games with a pool of allowed characters can only store a character ID from that pool.
+func validateCharacterAssignment(
+	gameParticipantsRow *GameParticipantsRow,
+	detrimentalCharacters bool,
+	allowedCharacters []int,
+) error {
+	characterAssignment := gameParticipantsRow.CharacterAssignment
+	valid := false
+	notAllowed := false
+	if !detrimentalCharacters {
+		valid = characterAssignment == 0
+	} else if characterAssignment == -1 {
+		valid = true
+	} else if _, ok := characterIDMap[characterAssignment]; ok {
+		valid = len(allowedCharacters) == 0 || intInSlice(characterAssignment, allowedCharacters)
+		notAllowed = !valid
+	}
+
+	if !valid {
+		return &InvalidCharacterAssignmentError{
+			Seat:                  gameParticipantsRow.Seat,
+			CharacterAssignment:   characterAssignment,
+			DetrimentalCharacters: detrimentalCharacters,
+			NotAllowed:            notAllowed,
+		}
+	}
+
+	return nil
+}
+
+// BatchInsert writes every participant of a game with a single multi-row insert
+// It is performed inside of a transaction so that a failure will never leave behind a game with
+// only some of its participants written
+// An "*InvalidCharacterAssignmentError" is returned (before anything is written) if any of the
+// character assignments are not valid for the game
+func (*GameParticipants) BatchInsert(
+	gameID int,
+	detrimentalCharacters bool,
+	allowedCharacters []int,
+	gameParticipantsRows []*GameParticipantsRow,
+) error {
+	var tx pgx.Tx
+	if v, err := db.Begin(context.Background()); err != nil {
+		return err
+	} else {
+		tx = v
+	}
+	// Rolling back a transaction that has already been committed is a no-op
+	defer tx.Rollback(context.Background()) // nolint: errcheck
+
+	if err := gameParticipantsBatchInsert(
+		tx,
+		gameID,
+		detrimentalCharacters,
+		allowedCharacters,
+		gameParticipantsRows,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit(context.Background())
+}
+
+func gameParticipantsBatchInsert(
+	tx pgx.Tx,
+	gameID int,
+	detrimentalCharacters bool,
+	allowedCharacters []int,
+	gameParticipantsRows []*GameParticipantsRow,
+) error {
+	for _, gameParticipantsRow := range gameParticipantsRows {
+		if err := validateCharacterAssignment(
+			gameParticipantsRow,
+			detrimentalCharacters,
+			allowedCharacters,
+		); err != nil {
+			return err
+		}
+	}
+
+	// Insert the rows in seat order
+	// (we sort a copy so that the slice of the caller is not modified)
+	sortedRows := make([]*GameParticipantsRow, len(gameParticipantsRows))
+	copy(sortedRows, gameParticipantsRows)
+	sort.SliceStable(sortedRows, func(i, j int) bool {
+		return sortedRows[i].Seat < sortedRows[j].Seat
+	})
+
 	SQLString := `
 		INSERT INTO game_participants (
 			game_id,
 			user_id,
 			seat,
 			character_assignment,
-			character_metadata
+			character_metadata,
+			datetime_disconnected
 		)
 		VALUES %s
 	`
-	numArgsPerRow := 5
-	valueArgs := make([]interface{}, 0, numArgsPerRow*len(gameParticipantsRows))
-	for _, gameParticipantsRow := range gameParticipantsRows {
+	numArgsPerRow := 6
+	valueArgs := make([]interface{}, 0, numArgsPerRow*len(sortedRows))
+	for _, gameParticipantsRow := range sortedRows {
+		var characterMetadataJSON []byte
+		if v, err := json.Marshal(gameParticipantsRow.CharacterMetadata); err != nil {
+			return err
+		} else {
+			characterMetadataJSON = v
+		}
+
 		valueArgs = append(
 			valueArgs,
-			gameParticipantsRow.GameID,
+			gameID,
 			gameParticipantsRow.UserID,
 			gameParticipantsRow.Seat,
 			gameParticipantsRow.CharacterAssignment,
-			gameParticipantsRow.CharacterMetadata,
+			string(characterMetadataJSON),
+			gameParticipantsRow.DatetimeDisconnected,
 		)
 	}
-	SQLString = getBulkInsertSQLSimple(SQLString, numArgsPerRow, len(gameParticipantsRows))
+	SQLString = getBulkInsertSQLSimple(SQLString, numArgsPerRow, len(sortedRows))
 
-	_, err := db.Exec(context.Background(), SQLString, valueArgs...)
+	_, err := tx.Exec(context.Background(), SQLString, valueArgs...)
 	return err
 }
+
+// GetByGameID returns the participants of a game ordered by seat
+// An empty slice is returned if the game does not exist
+func (*GameParticipants) GetByGameID(gameID int) ([]*GameParticipantsRow, error) {
+	gameParticipantsRows := make([]*GameParticipantsRow, 0)
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT
+			game_participants.user_id,
+			users.username,
+			game_participants.seat,
+			game_participants.character_assignment,
+			game_participants.character_metadata,
+			game_participants.datetime_disconnected
+		FROM game_participants
+			JOIN users ON game_participants.user_id = users.id
+		WHERE game_participants.game_id = $1
+		ORDER BY game_participants.seat ASC
+	`, gameID); err != nil {
+		return gameParticipantsRows, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		gameParticipantsRow := GameParticipantsRow{ // nolint: exhaustivestruct
+			GameID: gameID,
+		}
+		var characterMetadataJSON []byte
+		if err := rows.Scan(
+			&gameParticipantsRow.UserID,
+			&gameParticipantsRow.Username,
+			&gameParticipantsRow.Seat,
+			&gameParticipantsRow.CharacterAssignment,
+			&characterMetadataJSON,
+			&gameParticipantsRow.DatetimeDisconnected,
+		); err != nil {
+			return gameParticipantsRows, err
+		}
+
+		if v, err := parseDBCharacterMetadata(characterMetadataJSON); err != nil {
+			return gameParticipantsRows, err
+		} else {
+			gameParticipantsRow.CharacterMetadata = v
+		}
+
+		gameParticipantsRows = append(gameParticipantsRows, &gameParticipantsRow)
+	}
+
+	if err := rows.Err(); err != nil {
+		return gameParticipantsRows, err
+	}
+	rows.Close()
+
+	return gameParticipantsRows, nil
+}
+
+// MarkDisconnected records that a player has left their seat
+// Only the latest disconnection is stored
+// The rows of a game are only written when the game ends (in the "gameEnd()" function),
+// so ongoing games are tracked in memory with "Player.DatetimeDisconnected" instead
+func (*GameParticipants) MarkDisconnected(gameID int, userID int) error {
+	_, err := db.Exec(context.Background(), `
+		UPDATE game_participants
+		SET datetime_disconnected = NOW()
+		WHERE game_id = $1
+			AND user_id = $2
+	`, gameID, userID)
+	return err
+}
+
+// MarkReconnected clears the disconnection time of a player
+// (reconnecting does not keep any history of the previous disconnection)
+func (*GameParticipants) MarkReconnected(gameID int, userID int) error {
+	_, err := db.Exec(context.Background(), `
+		UPDATE game_participants
+		SET datetime_disconnected = NULL
+		WHERE game_id = $1
+			AND user_id = $2
+	`, gameID, userID)
+	return err
+}
+
+// GetPairStats returns the number of games that two players have completed together and how many
+// of those games reached the maximum score for the variant
+// Games that did not run to completion (e.g. terminated, abandoned, or resigned games) are not
+// counted at all, so they do not lower the win rate
+func (*GameParticipants) GetPairStats(userA int, userB int) (int, int, error) {
+	if userA == userB {
+		return 0, 0, errors.New("cannot get the pair stats of user " + strconv.Itoa(userA) +
+			" with themselves")
+	}
+
+	// The maximum score for each variant is not stored in the database,
+	// so we pass them to the query
+	variantIDs, maxScores := getVariantMaxScores()
+
+	// Since there can only be one row per user in a game,
+	// joining the two players together produces exactly one row per shared game
+	var numGames int
+	var numWins int
+	if err := db.QueryRow(context.Background(), `
+		SELECT
+			COUNT(games.id) AS num_games,
+			COUNT(games.id) FILTER (WHERE games.score = max_scores.max_score) AS num_wins
+		FROM game_participants AS player_a
+			JOIN game_participants AS player_b ON player_a.game_id = player_b.game_id
+			JOIN games ON player_a.game_id = games.id
+			JOIN UNNEST($3::INTEGER[], $4::INTEGER[]) AS max_scores (variant_id, max_score)
+				ON games.variant_id = max_scores.variant_id
+		WHERE player_a.user_id = $1
+			AND player_b.user_id = $2
+			AND games.datetime_deleted IS NULL
+			AND games.end_condition NOT IN ($5, $6, $7, $8, $9)
+	`,
+		userA,
+		userB,
+		variantIDs,
+		maxScores,
+		EndConditionTerminatedByPlayer,
+		EndConditionIdleTimeout,
+		EndConditionTerminatedByVote,
+		EndConditionAbandoned,
+		EndConditionResigned,
+	).Scan(&numGames, &numWins); err != nil {
+		return 0, 0, err
+	}
+
+	return numGames, numWins, nil
+}
+
+// FindGamesWithUsers returns the IDs of the games that all of the given users played in together,
+// ordered from the most recent game to the oldest game
+// If "exact" is true, games that also had other players in them are excluded
+// (i.e. the set of participants must be exactly equal to the given users)
+func (*GameParticipants) FindGamesWithUsers(userIDs []int, exact bool) ([]int, error) {
+	gameIDs := make([]int, 0)
+
+	// Ignore duplicate users, since they would otherwise make the count impossible to match
+	uniqueUserIDs := make([]int, 0, len(userIDs))
+	userIDMap := make(map[int]struct{})
+	for _, userID := range userIDs {
+		if _, ok := userIDMap[userID]; ok {
+			continue
+		}
+		userIDMap[userID] = struct{}{}
+		uniqueUserIDs = append(uniqueUserIDs, userID)
+	}
+	if len(uniqueUserIDs) == 0 {
+		return gameIDs, errors.New("at least one user must be specified")
+	}
+
+	// Since there can only be one row per user in a game,
+	// a game contains all of the users if the number of matching rows is equal to the number of
+	// users; for an exact match, the game must also have no other rows
+	// The games are first narrowed down to the ones that any of the users played in so that we do
+	// not have to group the entire table
+	SQLString := `
+		SELECT game_participants.game_id
+		FROM game_participants
+			JOIN games ON game_participants.game_id = games.id
+		WHERE game_participants.game_id IN (
+			SELECT game_id
+			FROM game_participants
+			WHERE user_id = ANY($1)
+		)
+			AND games.datetime_deleted IS NULL
+		GROUP BY game_participants.game_id
+		HAVING COUNT(*) FILTER (WHERE game_participants.user_id = ANY($1)) = $2
+	`
+	if exact {
+		SQLString += `
+			AND COUNT(*) = $2
+		`
+	}
+	SQLString += `
+		ORDER BY game_participants.game_id DESC
+	`
+
+	var rows pgx.Rows
+	if v, err := db.Query(
+		context.Background(),
+		SQLString,
+		uniqueUserIDs,
+		len(uniqueUserIDs),
+	); err != nil {
+		return gameIDs, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var gameID int
+		if err := rows.Scan(&gameID); err != nil {
+			return gameIDs, err
+		}
+		gameIDs = append(gameIDs, gameID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return gameIDs, err
+	}
+	rows.Close()
+
+	return gameIDs, nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestFindGamesWithUsers writes fixtures to a real database,
+// so it only runs if the "TEST_DATABASE" environment variable is set
+// The database is configured with the same environment variables as the server
+// (e.g. "DB_HOST" and "DB_NAME")
+func TestFindGamesWithUsers(t *testing.T) {
+	if os.Getenv("TEST_DATABASE") == "" {
+		t.Skip("TEST_DATABASE is not set")
+	}
+
+	var m *Models
+	if v, err := modelsInit(); err != nil {
+		t.Fatalf("failed to connect to the database: %v", err)
+	} else {
+		m = v
+	}
+	defer m.Close()
+
+	// Create the users
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 10)
+	userIDs := make([]int, 0)
+	defer func() {
+		// Deleting the users also deletes their participant rows,
+		// which deletes the games with the "delete_game_upon_participant_deletion" trigger
+		if _, err := db.Exec(context.Background(), `
+			DELETE FROM users
+			WHERE id = ANY($1)
+		`, userIDs); err != nil {
+			t.Errorf("failed to delete the test users: %v", err)
+		}
+	}()
+	for i := 0; i < 3; i++ {
+		username := "test-" + suffix + "-" + strconv.Itoa(i)
+		if user, err := m.Users.Insert(username, username, "", "127.0.0.1"); err != nil {
+			t.Fatalf("failed to insert user \"%v\": %v", username, err)
+		} else {
+			userIDs = append(userIDs, user.ID)
+		}
+	}
+	alice := userIDs[0]
+	bob := userIDs[1]
+	cathy := userIDs[2]
+
+	insertGame := func(gameUserIDs ...int) int {
+		options := NewOptions()
+		options.NumPlayers = len(gameUserIDs)
+
+		var gameID int
+		if v, err := m.Games.Insert(GameRow{
+			Name:             "test-" + suffix,
+			Options:          options,
+			Seed:             "p" + strconv.Itoa(len(gameUserIDs)) + "v0s1",
+			Score:            0,
+			NumTurns:         0,
+			NumStrikes:       0,
+			EndCondition:     EndConditionNormal,
+			DatetimeStarted:  time.Now(),
+			DatetimeFinished: time.Now(),
+		}); err != nil {
+			t.Fatalf("failed to insert a game: %v", err)
+		} else {
+			gameID = v
+		}
+
+		gameParticipantsRows := make([]*GameParticipantsRow, 0, len(gameUserIDs))
+		for seat, userID := range gameUserIDs {
+			gameParticipantsRows = append(gameParticipantsRows, &GameParticipantsRow{ // nolint: exhaustivestruct
+				UserID:            userID,
+				Seat:              seat,
+				CharacterMetadata: NewDBCharacterMetadata(-1),
+			})
+		}
+		if err := m.GameParticipants.BatchInsert(
+			gameID,
+			false,
+			nil,
+			gameParticipantsRows,
+		); err != nil {
+			t.Fatalf("failed to insert the participants of game %v: %v", gameID, err)
+		}
+
+		return gameID
+	}
+
+	// The games are inserted from oldest to newest
+	aliceAndBob := insertGame(alice, bob)
+	aliceBobAndCathy := insertGame(alice, bob, cathy)
+	aliceAndCathy := insertGame(alice, cathy)
+	deleted := insertGame(alice, bob)
+	if _, err := db.Exec(context.Background(), `
+		UPDATE games
+		SET datetime_deleted = NOW()
+		WHERE id = $1
+	`, deleted); err != nil {
+		t.Fatalf("failed to delete game %v: %v", deleted, err)
+	}
+
+	tests := []struct {
+		name    string
+		userIDs []int
+		exact   bool
+		want    []int
+	}{
+		{
+			name:    "one user",
+			userIDs: []int{alice},
+			exact:   false,
+			want:    []int{aliceAndCathy, aliceBobAndCathy, aliceAndBob},
+		},
+		{
+			name:    "two users",
+			userIDs: []int{alice, bob},
+			exact:   false,
+			want:    []int{aliceBobAndCathy, aliceAndBob},
+		},
+		{
+			name:    "two users with an exact match",
+			userIDs: []int{alice, bob},
+			exact:   true,
+			want:    []int{aliceAndBob},
+		},
+		{
+			name:    "duplicate users",
+			userIDs: []int{alice, bob, bob},
+			exact:   true,
+			want:    []int{aliceAndBob},
+		},
+		{
+			name:    "three users",
+			userIDs: []int{cathy, bob, alice},
+			exact:   false,
+			want:    []int{aliceBobAndCathy},
+		},
+		{
+			name:    "no games together",
+			userIDs: []int{bob, cathy},
+			exact:   true,
+			want:    []int{},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			gameIDs, err := m.GameParticipants.FindGamesWithUsers(test.userIDs, test.exact)
+			if err != nil {
+				t.Fatalf("failed to find the games: %v", err)
+			}
+			if !reflect.DeepEqual(gameIDs, test.want) {
+				t.Errorf("got %v, want %v", gameIDs, test.want)
+			}
+		})
+	}
+
+	t.Run("no users", func(t *testing.T) {
+		if _, err := m.GameParticipants.FindGamesWithUsers([]int{}, false); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
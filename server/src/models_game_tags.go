@@ -2,6 +2,9 @@ package main
 
 import (
 	"context"
+	"errors"
+	"strconv"
+	"strings"
 
 	"github.com/jackc/pgx/v4"
 )
@@ -14,10 +17,22 @@ type GameTagsRow struct {
 	Tag    string
 }
 
+// Insert adds a tag for a user
+// Whitespace is trimmed from the tag
+// Inserting a tag that the user has already added to the game does nothing
 func (*GameTags) Insert(gameID int, userID int, tag string) error {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return errors.New("the tag is blank")
+	}
+	if len(tag) > MaxTagLength {
+		return errors.New("the tag is longer than " + strconv.Itoa(MaxTagLength) + " characters")
+	}
+
 	_, err := db.Exec(context.Background(), `
 		INSERT INTO game_tags (game_id, user_id, tag)
 		VALUES ($1, $2, $3)
+		ON CONFLICT (game_id, user_id, tag) DO NOTHING
 	`, gameID, userID, tag)
 	return err
 }
@@ -38,12 +53,15 @@ func (*GameTags) BulkInsert(gameTagsRows []*GameTagsRow) error {
 	return err
 }
 
-func (*GameTags) Delete(gameID int, tag string) error {
+// Delete removes a tag that a user added to a game
+// (tags are per-user, so the tags of other users on the same game are not affected)
+func (*GameTags) Delete(gameID int, userID int, tag string) error {
 	_, err := db.Exec(context.Background(), `
 		DELETE FROM game_tags
 		WHERE game_id = $1
-			AND tag = $2
-	`, gameID, tag)
+			AND user_id = $2
+			AND tag = $3
+	`, gameID, userID, tag)
 	return err
 }
 
@@ -177,3 +195,14 @@ func (*GameTags) SearchByUserID(userID int) (map[int][]string, error) {
 
 	return gamesMap, nil
 }
+
+// GetByGame returns the tags that a user added to a game
+// Tags are per-user, so the tags of other users are never returned
+func (gt *GameTags) GetByGame(gameID int, userID int) ([]string, error) {
+	return gt.GetAllByUserID(gameID, userID)
+}
+
+// GetByUser returns every game that a user has tagged, along with their tags for that game
+func (gt *GameTags) GetByUser(userID int) (map[int][]string, error) {
+	return gt.SearchByUserID(userID)
+}
@@ -2,7 +2,13 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -21,6 +27,7 @@ type GameRow struct {
 	Seed             string
 	Score            int
 	NumTurns         int
+	NumStrikes       int
 	EndCondition     int
 	DatetimeStarted  time.Time
 	DatetimeFinished time.Time
@@ -38,13 +45,17 @@ type GamesRow struct {
 }
 
 func (*Games) Insert(gameRow GameRow) (int, error) {
+	return gamesInsert(db, gameRow)
+}
+
+func gamesInsert(q dbQuerier, gameRow GameRow) (int, error) {
 	// Local variables
 	variant := variants[gameRow.Options.VariantName]
 
 	// https://www.postgresql.org/docs/9.5/dml-returning.html
 	// https://github.com/jackc/pgx/issues/411
 	var id int
-	if err := db.QueryRow(
+	if err := q.QueryRow(
 		context.Background(),
 		`
 			INSERT INTO games (
@@ -55,6 +66,7 @@ func (*Games) Insert(gameRow GameRow) (int, error) {
 				time_base,
 				time_per_turn,
 				speedrun,
+				turn_time_limit,
 				card_cycle,
 				deck_plays,
 				empty_clues,
@@ -62,9 +74,11 @@ func (*Games) Insert(gameRow GameRow) (int, error) {
 				one_less_card,
 				all_or_nothing,
 				detrimental_characters,
+				allowed_characters,
 				seed,
 				score,
 				num_turns,
+				num_strikes,
 				end_condition,
 				datetime_started,
 				datetime_finished
@@ -88,7 +102,10 @@ func (*Games) Insert(gameRow GameRow) (int, error) {
 				$17,
 				$18,
 				$19,
-				$20
+				$20,
+				$21,
+				$22,
+				$23
 			)
 			RETURNING id
 		`,
@@ -101,6 +118,7 @@ func (*Games) Insert(gameRow GameRow) (int, error) {
 		gameRow.Options.TimeBase,
 		gameRow.Options.TimePerTurn,
 		gameRow.Options.Speedrun,
+		gameRow.Options.TurnTimeLimit,
 		gameRow.Options.CardCycle,
 		gameRow.Options.DeckPlays,
 		gameRow.Options.EmptyClues,
@@ -108,9 +126,11 @@ func (*Games) Insert(gameRow GameRow) (int, error) {
 		gameRow.Options.OneLessCard,
 		gameRow.Options.AllOrNothing,
 		gameRow.Options.DetrimentalCharacters,
+		gameRow.Options.AllowedCharacters,
 		gameRow.Seed,
 		gameRow.Score,
 		gameRow.NumTurns,
+		gameRow.NumStrikes,
 		gameRow.EndCondition,
 		gameRow.DatetimeStarted,
 		gameRow.DatetimeFinished,
@@ -121,6 +141,460 @@ func (*Games) Insert(gameRow GameRow) (int, error) {
 	return id, nil
 }
 
+// InsertFromJSON inserts a game that was specified by a JSON deck definition (e.g. a puzzle)
+// Unlike normal games, the deck is stored explicitly, since it cannot be derived from the seed
+// All of the inserts are performed inside of a single transaction so that a failure will never
+// leave behind a partially-inserted game
+func (g *Games) InsertFromJSON(def GameJSONDefinition) (int, error) {
+	// Local variables
+	gameJSON := def.GameJSON
+	variant := variants[def.GameRow.Options.VariantName]
+
+	// Validate everything before inserting anything so that we never end up with a broken game
+	if valid, msg := isJSONDeckValid(variant, gameJSON.Deck); !valid {
+		return -1, errors.New(msg)
+	}
+	if len(def.UserIDs) != len(gameJSON.Players) {
+		return -1, errors.New("there are " + strconv.Itoa(len(gameJSON.Players)) + " players " +
+			"but " + strconv.Itoa(len(def.UserIDs)) + " user IDs")
+	}
+	if def.GameRow.Options.DetrimentalCharacters &&
+		len(gameJSON.Characters) != len(gameJSON.Players) {
+
+		return -1, errors.New("the amount of characters does not match the number of players")
+	}
+
+	row := def.GameRow
+	row.Seed = getJSONDeckSeed(len(gameJSON.Players), variant.ID, gameJSON.Deck)
+
+	var tx pgx.Tx
+	if v, err := db.Begin(context.Background()); err != nil {
+		return -1, err
+	} else {
+		tx = v
+	}
+	// Rolling back a transaction that has already been committed is a no-op
+	defer tx.Rollback(context.Background()) // nolint: errcheck
+
+	var gameID int
+	if v, err := gamesInsert(tx, row); err != nil {
+		return -1, err
+	} else {
+		gameID = v
+	}
+
+	if err := insertFromJSONRows(tx, gameID, def); err != nil {
+		return -1, err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		return -1, err
+	}
+
+	if err := models.Seeds.UpdateNumGames(row.Seed); err != nil {
+		logger.Error("Failed to update the number of games in the seeds table: " + err.Error())
+		// Do not return on a failed seeds update,
+		// since it should not affect subsequent operations
+	}
+
+	return gameID, nil
+}
+
+// AnonymizedGame is a game with all of the information about the players removed except for
+// their seat and their character (if any), for use in research
+type AnonymizedGame struct {
+	Seed         string              `json:"seed"`
+	Variant      string              `json:"variant"`
+	NumPlayers   int                 `json:"numPlayers"`
+	Score        int                 `json:"score"`
+	EndCondition int                 `json:"endCondition"`
+	Players      []*AnonymizedPlayer `json:"players"`
+	// Deck is only included for games that were imported from a JSON deck definition,
+	// since the deck for every other game can be derived from the seed
+	Deck    []*CardIdentity `json:"deck,omitempty"`
+	Actions []*GameAction   `json:"actions"`
+}
+
+type AnonymizedPlayer struct {
+	Seat      int                  `json:"seat"`
+	Character *CharacterAssignment `json:"character,omitempty"`
+}
+
+// ExportAnonymized writes every completed game that finished in between the two times to the
+// writer as newline-delimited JSON (with one game per line)
+// Each game is written as soon as it is read from the database so that the memory usage stays
+// the same regardless of how many games are in the range
+func (*Games) ExportAnonymized(from time.Time, to time.Time, w io.Writer) error {
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT
+			games.seed,
+			games.variant_id,
+			games.num_players,
+			games.score,
+			games.end_condition,
+			(
+				SELECT json_agg(json_build_object(
+					'seat', game_participants.seat,
+					'characterAssignment', game_participants.character_assignment,
+					'characterMetadata', game_participants.character_metadata
+				) ORDER BY game_participants.seat)
+				FROM game_participants
+				WHERE game_participants.game_id = games.id
+			) AS players,
+			(
+				SELECT COALESCE(json_agg(json_build_object(
+					'suitIndex', game_decks.suit_index,
+					'rank', game_decks.rank
+				) ORDER BY game_decks.card_order), '[]')
+				FROM game_decks
+				WHERE game_decks.game_id = games.id
+			) AS deck,
+			(
+				SELECT COALESCE(json_agg(json_build_object(
+					'type', game_actions.type,
+					'target', game_actions.target,
+					'value', game_actions.value
+				) ORDER BY game_actions.turn), '[]')
+				FROM game_actions
+				WHERE game_actions.game_id = games.id
+			) AS actions
+		FROM games
+		WHERE games.datetime_finished >= $1
+			AND games.datetime_finished < $2
+			AND games.datetime_deleted IS NULL
+			AND games.end_condition NOT IN ($3, $4, $5, $6)
+		ORDER BY games.datetime_finished, games.id
+	`,
+		from,
+		to,
+		EndConditionTerminatedByPlayer,
+		EndConditionIdleTimeout,
+		EndConditionTerminatedByVote,
+		EndConditionAbandoned,
+	); err != nil {
+		return err
+	} else {
+		rows = v
+	}
+	defer rows.Close()
+
+	encoder := json.NewEncoder(w)
+	for rows.Next() {
+		var game AnonymizedGame
+		var variantID int
+		var playersJSON []byte
+		var deckJSON []byte
+		var actionsJSON []byte
+		if err := rows.Scan(
+			&game.Seed,
+			&variantID,
+			&game.NumPlayers,
+			&game.Score,
+			&game.EndCondition,
+			&playersJSON,
+			&deckJSON,
+			&actionsJSON,
+		); err != nil {
+			return err
+		}
+		game.Variant = variantIDMap[variantID]
+
+		if v, err := getAnonymizedPlayers(playersJSON); err != nil {
+			return err
+		} else {
+			game.Players = v
+		}
+		if err := json.Unmarshal(deckJSON, &game.Deck); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(actionsJSON, &game.Actions); err != nil {
+			return err
+		}
+
+		// The encoder adds a newline after each game
+		if err := encoder.Encode(&game); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func getAnonymizedPlayers(playersJSON []byte) ([]*AnonymizedPlayer, error) {
+	var participants []struct {
+		Seat                int             `json:"seat"`
+		CharacterAssignment int             `json:"characterAssignment"`
+		CharacterMetadata   json.RawMessage `json:"characterMetadata"`
+	}
+	if err := json.Unmarshal(playersJSON, &participants); err != nil {
+		return nil, err
+	}
+
+	players := make([]*AnonymizedPlayer, 0, len(participants))
+	for _, participant := range participants {
+		player := &AnonymizedPlayer{
+			Seat:      participant.Seat,
+			Character: nil,
+		}
+
+		// A character assignment of 0 means that the game did not have characters
+		if participant.CharacterAssignment != 0 {
+			var characterMetadata DBCharacterMetadata
+			if v, err := parseDBCharacterMetadata(participant.CharacterMetadata); err != nil {
+				return nil, err
+			} else {
+				characterMetadata = v
+			}
+
+			// A character assignment of -1 means that the player did not have a character
+			// (which is represented as "n/a" in JSON games)
+			characterName := "n/a"
+			if participant.CharacterAssignment != -1 {
+				characterName = characterIDMap[participant.CharacterAssignment]
+			}
+			player.Character = &CharacterAssignment{
+				Name:     characterName,
+				Metadata: characterMetadata.Value(),
+			}
+		}
+
+		players = append(players, player)
+	}
+
+	return players, nil
+}
+
+// InsertWithState inserts a game that is in progress with the specified state,
+// so that edge cases of the game logic can be tested without playing through an entire game
+// A plausible list of actions is generated that leads to the state
+// This is only allowed in development
+func (g *Games) InsertWithState(def GameStateDefinition) (int, error) {
+	if !isDev {
+		return -1, errors.New("games with a custom state can only be inserted in development")
+	}
+
+	var gameJSON *GameJSON
+	if v, err := getGameStateJSON(def); err != nil {
+		return -1, err
+	} else {
+		gameJSON = v
+	}
+
+	var row GameRow
+	if v, err := emulateGameState(def, gameJSON); err != nil {
+		return -1, err
+	} else {
+		row = v
+	}
+
+	return g.InsertFromJSON(GameJSONDefinition{
+		GameRow:  row,
+		GameJSON: gameJSON,
+		UserIDs:  def.UserIDs,
+	})
+}
+
+func insertFromJSONRows(tx pgx.Tx, gameID int, def GameJSONDefinition) error {
+	// Local variables
+	gameJSON := def.GameJSON
+
+	// The seat of each player is the order that they were specified in the JSON
+	gameParticipantsRows := make([]*GameParticipantsRow, 0)
+	for i, name := range gameJSON.Players {
+		characterID := 0
+		characterMetadata := NewDBCharacterMetadata(-1)
+		if def.GameRow.Options.DetrimentalCharacters {
+			characterAssignment := gameJSON.Characters[i]
+			if characterAssignment.Name == "n/a" {
+				characterID = -1
+			} else if v, ok := characters[characterAssignment.Name]; !ok {
+				return errors.New("the character of " + characterAssignment.Name +
+					" does not exist in the characters map")
+			} else {
+				characterID = v.ID
+				if v.WriteMetadataToDatabase {
+					characterMetadata = NewDBCharacterMetadata(characterAssignment.Metadata)
+				}
+			}
+		}
+
+		gameParticipantsRows = append(gameParticipantsRows, &GameParticipantsRow{
+			GameID:               gameID,
+			UserID:               def.UserIDs[i],
+			Seat:                 i,
+			CharacterAssignment:  characterID,
+			CharacterMetadata:    characterMetadata,
+			DatetimeDisconnected: sql.NullTime{}, // nolint: exhaustivestruct
+			Username:             name,
+		})
+	}
+	if err := gameParticipantsBatchInsert(
+		tx,
+		gameID,
+		def.GameRow.Options.DetrimentalCharacters,
+		def.GameRow.Options.AllowedCharacters,
+		gameParticipantsRows,
+	); err != nil {
+		return err
+	}
+
+	gameActionRows := make([]*GameActionRow, 0)
+	for i, action := range gameJSON.Actions {
+		gameActionRows = append(gameActionRows, &GameActionRow{
+			GameID:    gameID,
+			Turn:      i,
+			Type:      action.Type,
+			Target:    action.Target,
+			Value:     action.Value,
+			ThinkTime: sql.NullInt32{}, // nolint: exhaustivestruct
+		})
+	}
+	if len(gameActionRows) > 0 {
+		if err := gameActionsBulkInsert(tx, gameActionRows); err != nil {
+			return err
+		}
+	}
+
+	gameDeckRows := make([]*GameDeckRow, 0)
+	for i, card := range gameJSON.Deck {
+		gameDeckRows = append(gameDeckRows, &GameDeckRow{
+			GameID:    gameID,
+			CardOrder: i,
+			SuitIndex: card.SuitIndex,
+			Rank:      card.Rank,
+		})
+	}
+	if err := gameDecksBulkInsert(tx, gameDeckRows); err != nil {
+		return err
+	}
+
+	gameParticipantNotesRows := make([]*GameParticipantNotesRow, 0)
+	for i, playerNotes := range gameJSON.Notes {
+		for j, note := range playerNotes {
+			if note == "" {
+				continue
+			}
+
+			gameParticipantNotesRows = append(gameParticipantNotesRows, &GameParticipantNotesRow{
+				GameID:    gameID,
+				UserID:    def.UserIDs[i],
+				CardOrder: j,
+				Note:      note,
+			})
+		}
+	}
+	if len(gameParticipantNotesRows) > 0 {
+		if err := gameParticipantNotesBulkInsert(tx, gameParticipantNotesRows); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SoftDelete hides a game from the history, the leaderboards, and the stats
+// The row is kept so that the game can still be audited (or restored)
+func (g *Games) SoftDelete(gameID int, moderatorID int) error {
+	if tag, err := db.Exec(context.Background(), `
+		UPDATE games
+		SET
+			datetime_deleted = NOW(),
+			deleted_by = $2
+		WHERE id = $1
+			AND datetime_deleted IS NULL
+	`, gameID, moderatorID); err != nil {
+		return err
+	} else if tag.RowsAffected() == 0 {
+		return errors.New("game " + strconv.Itoa(gameID) + " does not exist or is already deleted")
+	}
+
+	g.writeModLog(gameID, moderatorID, ModLogActionDeleteGame)
+
+	return g.recalculateStats(gameID)
+}
+
+// Restore undoes a soft deletion
+func (g *Games) Restore(gameID int, moderatorID int) error {
+	if tag, err := db.Exec(context.Background(), `
+		UPDATE games
+		SET
+			datetime_deleted = NULL,
+			deleted_by = NULL
+		WHERE id = $1
+			AND datetime_deleted IS NOT NULL
+	`, gameID); err != nil {
+		return err
+	} else if tag.RowsAffected() == 0 {
+		return errors.New("game " + strconv.Itoa(gameID) + " does not exist or is not deleted")
+	}
+
+	g.writeModLog(gameID, moderatorID, ModLogActionRestoreGame)
+
+	return g.recalculateStats(gameID)
+}
+
+// writeModLog records a moderator action on a game against every player in the game
+// The game has already been changed by this point, so we only log errors
+func (g *Games) writeModLog(gameID int, moderatorID int, action string) {
+	var dbPlayers []*DBPlayer
+	if v, err := g.GetPlayers(gameID); err != nil {
+		logger.Error("Failed to get the players for game " + strconv.Itoa(gameID) + ": " +
+			err.Error())
+		return
+	} else {
+		dbPlayers = v
+	}
+
+	reason := "Game #" + strconv.Itoa(gameID)
+	for _, dbPlayer := range dbPlayers {
+		if err := models.ModLog.Insert(moderatorID, dbPlayer.ID, action, reason); err != nil {
+			logger.Error("Failed to insert the moderator log entry for game " +
+				strconv.Itoa(gameID) + ": " + err.Error())
+		}
+	}
+}
+
+// recalculateStats updates all of the stats that a game contributes to
+// The stats are normally updated incrementally at the end of a game,
+// so they have to be rebuilt when a game is deleted or restored
+// (otherwise, a cheated score could linger at the top of the best scores)
+func (g *Games) recalculateStats(gameID int) error {
+	var options *Options
+	if v, err := g.GetOptions(gameID); err != nil {
+		return err
+	} else {
+		options = v
+	}
+	variant := variants[options.VariantName]
+
+	var gameParticipantsRows []*GameParticipantsRow
+	if v, err := models.GameParticipants.GetByGameID(gameID); err != nil {
+		return err
+	} else {
+		gameParticipantsRows = v
+	}
+
+	for _, gameParticipantsRow := range gameParticipantsRows {
+		if err := models.UserStats.Recalculate(gameParticipantsRow.UserID, variant.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := models.VariantStats.Recalculate(variant.ID, variant.MaxScore); err != nil {
+		return err
+	}
+
+	var seed string
+	if v, err := g.GetSeed(gameID); err != nil {
+		return err
+	} else {
+		seed = v
+	}
+
+	return models.Seeds.UpdateNumGames(seed)
+}
+
 func (*Games) Exists(databaseID int) (bool, error) {
 	var id int
 	if err := db.QueryRow(context.Background(), `
@@ -187,6 +661,7 @@ func (*Games) GetHistoryCustomSort(gameIDs []int, sortMode string) ([]*GameHisto
 			games1.time_base,
 			games1.time_per_turn,
 			games1.speedrun,
+			games1.turn_time_limit,
 			games1.card_cycle,
 			games1.deck_plays,
 			games1.empty_clues,
@@ -255,6 +730,7 @@ func (*Games) GetHistoryCustomSort(gameIDs []int, sortMode string) ([]*GameHisto
 			&gameHistory.Options.TimeBase,
 			&gameHistory.Options.TimePerTurn,
 			&gameHistory.Options.Speedrun,
+			&gameHistory.Options.TurnTimeLimit,
 			&gameHistory.Options.CardCycle,
 			&gameHistory.Options.DeckPlays,
 			&gameHistory.Options.EmptyClues,
@@ -309,6 +785,7 @@ func (*Games) GetGameIDsUser(userID int, offset int, amount int) ([]int, error)
 		FROM games
 			JOIN game_participants ON games.id = game_participants.game_id
 		WHERE game_participants.user_id = $1
+			AND games.datetime_deleted IS NULL
 		/* We must get the results in descending order for the limit to work properly */
 		ORDER BY games.id DESC
 	`
@@ -347,6 +824,100 @@ func (*Games) GetGameIDsUser(userID int, offset int, amount int) ([]int, error)
 	return gameIDs, nil
 }
 
+// HistoryFilter narrows down the games returned by "GetHistoryForUser()"
+// Nil fields are not filtered on; an empty filter returns every game
+type HistoryFilter struct {
+	VariantID  *int
+	NumPlayers *int
+	From       *time.Time // Inclusive, compared against the start of the game
+	To         *time.Time // Exclusive, compared against the start of the game
+	// True for only games that reached the maximum score, false for only games that did not
+	Won *bool
+
+	// If Amount is 0, all of the matching games are returned
+	Offset int
+	Amount int
+}
+
+// GetHistoryForUser returns the games that a user played that match the filter,
+// with the newest game first
+func (g *Games) GetHistoryForUser(userID int, filter HistoryFilter) ([]*GameHistory, error) {
+	SQLString := `
+		SELECT games.id
+		FROM games
+			JOIN game_participants ON games.id = game_participants.game_id
+	`
+	args := []interface{}{userID}
+	where := `
+		WHERE game_participants.user_id = $1
+			AND games.datetime_deleted IS NULL
+	`
+
+	// Every value is passed as an argument so that nothing from the filter is put in the query
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		where += "AND " + condition + " $" + strconv.Itoa(len(args)) + " "
+	}
+	if filter.VariantID != nil {
+		addCondition("games.variant_id =", *filter.VariantID)
+	}
+	if filter.NumPlayers != nil {
+		addCondition("games.num_players =", *filter.NumPlayers)
+	}
+	if filter.From != nil {
+		addCondition("games.datetime_started >=", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("games.datetime_started <", *filter.To)
+	}
+	if filter.Won != nil {
+		// The maximum score for each variant is not stored in the database,
+		// so we pass them to the query
+		variantIDs, maxScores := getVariantMaxScores()
+		args = append(args, variantIDs, maxScores)
+		SQLString += `
+			JOIN UNNEST($` + strconv.Itoa(len(args)-1) + `::INTEGER[], $` +
+			strconv.Itoa(len(args)) + `::INTEGER[]) AS max_scores (variant_id, max_score)
+				ON games.variant_id = max_scores.variant_id
+		`
+		if *filter.Won {
+			where += "AND games.score = max_scores.max_score "
+		} else {
+			where += "AND games.score != max_scores.max_score "
+		}
+	}
+
+	// We must get the results in descending order for the limit to work properly
+	SQLString += where + "ORDER BY games.id DESC "
+	if filter.Amount > 0 {
+		args = append(args, filter.Amount, filter.Offset)
+		SQLString += "LIMIT $" + strconv.Itoa(len(args)-1) + " OFFSET $" + strconv.Itoa(len(args))
+	}
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), SQLString, args...); err != nil {
+		return nil, err
+	} else {
+		rows = v
+	}
+
+	gameIDs := make([]int, 0)
+	for rows.Next() {
+		var gameID int
+		if err := rows.Scan(&gameID); err != nil {
+			return nil, err
+		}
+		gameIDs = append(gameIDs, gameID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	return g.GetHistory(gameIDs)
+}
+
 func (*Games) GetGameIDsSeed(seed string) ([]int, error) {
 	gameIDs := make([]int, 0)
 
@@ -354,6 +925,7 @@ func (*Games) GetGameIDsSeed(seed string) ([]int, error) {
 		SELECT id
 		FROM games
 		WHERE seed = $1
+			AND games.datetime_deleted IS NULL
 	`
 
 	var rows pgx.Rows
@@ -379,6 +951,51 @@ func (*Games) GetGameIDsSeed(seed string) ([]int, error) {
 	return gameIDs, nil
 }
 
+// GetBySeed returns the IDs of every game that was played on a named seed from the "!seed" feature,
+// across every number of players and every variant
+// (e.g. a name of "showmatch-game-1" will match "p3v0sshowmatch-game-1")
+// The server only generates numeric seed suffixes and named seeds can never be numeric,
+// so a name can never match a seed that the server generated
+func (*Games) GetBySeed(seed string) ([]int, error) {
+	gameIDs := make([]int, 0)
+
+	// The name is used in a regular expression, so it must not contain any special characters
+	if !seedHasValidCharacters(seed) {
+		return gameIDs, errors.New("the seed name of \"" + seed + "\" has invalid characters")
+	}
+	if seedIsGenerated(seed) {
+		return gameIDs, errors.New("the seed name of \"" + seed + "\" is reserved for the server")
+	}
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT id
+		FROM games
+		WHERE seed ~ ('^p[0-9]+v[0-9]+s' || $1 || '$')
+			AND games.datetime_deleted IS NULL
+		ORDER BY id
+	`, seed); err != nil {
+		return gameIDs, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var gameID int
+		if err := rows.Scan(&gameID); err != nil {
+			return gameIDs, err
+		}
+		gameIDs = append(gameIDs, gameID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return gameIDs, err
+	}
+	rows.Close()
+
+	return gameIDs, nil
+}
+
 func (*Games) GetGameIDsFriends(
 	userID int,
 	friends map[int]struct{},
@@ -395,7 +1012,9 @@ func (*Games) GetGameIDsFriends(
 	SQLString := `
 		SELECT DISTINCT game_participants.game_id
 		FROM game_participants
+			JOIN games ON games.id = game_participants.game_id
 		WHERE game_participants.user_id = ANY ($1)
+			AND games.datetime_deleted IS NULL
 		EXCEPT
 		(
 			SELECT game_participants.game_id
@@ -516,6 +1135,8 @@ func (*Games) GetFullGameIDsMultiUser(userIDs []int, idStart *int, idEnd *int) (
 		SQLString += "AND games.id <= " + strconv.Itoa(*idEnd) + " "
 	}
 
+	SQLString += "WHERE games.datetime_deleted IS NULL"
+
 	var rows pgx.Rows
 	if v, err := db.Query(context.Background(), SQLString); err != nil {
 		return gameIDs, err
@@ -667,12 +1288,156 @@ func (*Games) GetGamesForVariantFromGameIDs(gameIDs []int, orderBy string) ([]AP
 	return dbRows, nil
 }
 
+type LeaderboardRow struct {
+	ID         int    `json:"id"`
+	NumPlayers int    `json:"num_players"`
+	Score      int    `json:"score"`
+	Users      string `json:"users"`
+	Duration   int    `json:"duration"` // In seconds
+	DateTime   string `json:"datetime"`
+	Seed       string `json:"seed"`
+}
+
+// GetVariantLeaderboard gets the best game of every team that has played the given variant
+// A team is a distinct set of players; only their highest scoring (and then fastest) game is
+// included
+// Games played with options that make the game easier are not eligible
+func (*Games) GetVariantLeaderboard(variantID int, offset int, limit int) ([]LeaderboardRow, error) {
+	leaderboardRows := make([]LeaderboardRow, 0)
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		WITH team_games AS (
+			SELECT
+				games.id,
+				games.num_players,
+				games.score,
+				STRING_AGG(users.username, ', ' ORDER BY LOWER(users.username)) AS usernames,
+				CAST(
+					EXTRACT(EPOCH FROM games.datetime_finished) -
+					EXTRACT(EPOCH FROM games.datetime_started)
+				AS INTEGER) AS duration,
+				TO_CHAR(games.datetime_finished, 'YYYY-MM-DD" - "HH24:MI:SS TZ') AS finished,
+				games.seed,
+				ARRAY_AGG(game_participants.user_id ORDER BY game_participants.user_id) AS team
+			FROM games
+				JOIN game_participants ON games.id = game_participants.game_id
+				JOIN users ON game_participants.user_id = users.id
+			WHERE games.variant_id = $1
+				AND games.datetime_deleted IS NULL
+				AND games.deck_plays = FALSE
+				AND games.empty_clues = FALSE
+				AND games.one_extra_card = FALSE
+				AND games.one_less_card = FALSE
+				AND games.all_or_nothing = FALSE
+			GROUP BY games.id
+		), ranked_team_games AS (
+			SELECT
+				*,
+				ROW_NUMBER() OVER (
+					PARTITION BY team
+					ORDER BY score DESC, duration ASC, id ASC
+				) AS team_rank
+			FROM team_games
+		)
+		SELECT
+			id,
+			num_players,
+			score,
+			usernames,
+			duration,
+			finished,
+			seed
+		FROM ranked_team_games
+		WHERE team_rank = 1
+		/* We sort by the game ID last so that ties are in a stable order across pages */
+		ORDER BY score DESC, duration ASC, id ASC
+		LIMIT $2 OFFSET $3
+	`, variantID, limit, offset); err != nil {
+		return leaderboardRows, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var leaderboardRow LeaderboardRow
+		if err := rows.Scan(
+			&leaderboardRow.ID,
+			&leaderboardRow.NumPlayers,
+			&leaderboardRow.Score,
+			&leaderboardRow.Users,
+			&leaderboardRow.Duration,
+			&leaderboardRow.DateTime,
+			&leaderboardRow.Seed,
+		); err != nil {
+			return leaderboardRows, err
+		}
+		leaderboardRows = append(leaderboardRows, leaderboardRow)
+	}
+
+	if err := rows.Err(); err != nil {
+		return leaderboardRows, err
+	}
+	rows.Close()
+
+	return leaderboardRows, nil
+}
+
+// GetPlayStats derives the number of successful plays and clue actions from the stored actions of a
+// game, along with the maximum score for the variant of the game
+// The stored actions do not record whether a play was successful,
+// but every strike is caused by a misplay, so we subtract the strikes to only count successful plays
+// Older games do not have the number of strikes recorded; for those games, we fall back to counting
+// every play (including misplays)
+func (*Games) GetPlayStats(databaseID int) (int, int, int, error) {
+	var variantID int
+	var cardsPlayed int
+	var cluesGiven int
+	if err := db.QueryRow(context.Background(), `
+		SELECT
+			games.variant_id,
+			(
+				SELECT COUNT(*)
+				FROM game_actions
+				WHERE game_actions.game_id = games.id
+					AND game_actions.type = $2
+			) - COALESCE(games.num_strikes, 0) AS cards_played,
+			(
+				SELECT COUNT(*)
+				FROM game_actions
+				WHERE game_actions.game_id = games.id
+					AND game_actions.type IN ($3, $4)
+			) AS clues_given
+		FROM games
+		WHERE games.id = $1
+	`,
+		databaseID,
+		ActionTypePlay,
+		ActionTypeColorClue,
+		ActionTypeRankClue,
+	).Scan(&variantID, &cardsPlayed, &cluesGiven); err != nil {
+		return 0, 0, 0, err
+	}
+
+	// The maximum score is not stored in the database, so we get it from the variant definition
+	var variant *Variant
+	if variantName, ok := variantIDMap[variantID]; !ok {
+		err := errors.New("failed to find a definition for variant " + strconv.Itoa(variantID))
+		return 0, 0, 0, err
+	} else {
+		variant = variants[variantName]
+	}
+
+	return cardsPlayed, cluesGiven, variant.MaxScore, nil
+}
+
 func (*Games) GetUserNumGames(userID int, includeSpeedrun bool) (int, error) {
 	SQLString := `
 		SELECT COUNT(games.id)
 		FROM games
 			JOIN game_participants ON games.id = game_participants.game_id
 		WHERE game_participants.user_id = $1
+			AND games.datetime_deleted IS NULL
 			AND games.end_condition = 1
 	`
 	if !includeSpeedrun {
@@ -685,6 +1450,287 @@ func (*Games) GetUserNumGames(userID int, includeSpeedrun bool) (int, error) {
 	return count, nil
 }
 
+type WinRateStat struct {
+	NumGames int
+	NumWins  int // A win is a game that reached the maximum score for the variant
+	WinRate  float64
+}
+
+// GetWinRateByPlayerCount returns the win rate of a user for each number of players,
+// indexed by the number of players
+// Player counts that the user has not played any games with are not included
+func (*Games) GetWinRateByPlayerCount(userID int) (map[int]WinRateStat, error) {
+	winRates := make(map[int]WinRateStat)
+
+	// The maximum score for each variant is not stored in the database,
+	// so we pass them to the query
+	variantIDs, maxScores := getVariantMaxScores()
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT
+			games.num_players,
+			COUNT(games.id) AS num_games,
+			COUNT(games.id) FILTER (WHERE games.score = max_scores.max_score) AS num_wins
+		FROM games
+			JOIN game_participants ON games.id = game_participants.game_id
+			JOIN UNNEST($2::INTEGER[], $3::INTEGER[]) AS max_scores (variant_id, max_score)
+				ON games.variant_id = max_scores.variant_id
+		WHERE game_participants.user_id = $1
+			AND games.datetime_deleted IS NULL
+			/* Resigned games are not losses, so they should not lower the win rate */
+			AND games.end_condition != $4
+		GROUP BY games.num_players
+	`, userID, variantIDs, maxScores, EndConditionResigned); err != nil {
+		return winRates, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var numPlayers int
+		var stat WinRateStat
+		if err := rows.Scan(
+			&numPlayers,
+			&stat.NumGames,
+			&stat.NumWins,
+		); err != nil {
+			return winRates, err
+		}
+		stat.WinRate = float64(stat.NumWins) / float64(stat.NumGames)
+		winRates[numPlayers] = stat
+	}
+
+	if err := rows.Err(); err != nil {
+		return winRates, err
+	}
+	rows.Close()
+
+	return winRates, nil
+}
+
+// GetAverageScore returns the average score of the games played in a variant since the given time,
+// along with the number of games that the average is based on
+// Games that were terminated (or resigned) have a score of 0 but do not reflect the quality of play,
+// so they are excluded
+func (*Games) GetAverageScore(variantID int, since time.Time) (float64, int, error) {
+	var averageScore sql.NullFloat64
+	var numGames int
+	if err := db.QueryRow(context.Background(), `
+		SELECT
+			AVG(score) AS average_score,
+			COUNT(id) AS num_games
+		FROM games
+		WHERE variant_id = $1
+			AND datetime_finished >= $2
+			AND datetime_deleted IS NULL
+			AND end_condition NOT IN ($3, $4, $5, $6, $7)
+	`,
+		variantID,
+		since,
+		EndConditionTerminatedByPlayer,
+		EndConditionIdleTimeout,
+		EndConditionTerminatedByVote,
+		EndConditionAbandoned,
+		EndConditionResigned,
+	).Scan(&averageScore, &numGames); err != nil {
+		return 0, 0, err
+	}
+
+	// The average will be null if there were no games
+	return averageScore.Float64, numGames, nil
+}
+
+type PerfectGameRow struct {
+	ID               int       `json:"id"`
+	NumPlayers       int       `json:"numPlayers"`
+	PlayerNames      string    `json:"playerNames"` // Ordered by seat
+	Seed             string    `json:"seed"`
+	DatetimeStarted  time.Time `json:"datetimeStarted"`
+	DatetimeFinished time.Time `json:"datetimeFinished"`
+}
+
+// GetPerfectGames returns the games in a variant that achieved the maximum score,
+// ordered by how fast they were completed
+func (*Games) GetPerfectGames(variantID int, limit int) ([]PerfectGameRow, error) {
+	perfectGames := make([]PerfectGameRow, 0)
+
+	// The maximum score for each variant is not stored in the database
+	// (and is not always 25, e.g. for variants with fewer suits or "Up or Down")
+	var maxScore int
+	if variantName, ok := variantIDMap[variantID]; !ok {
+		err := errors.New("failed to find a definition for variant " + strconv.Itoa(variantID))
+		return perfectGames, err
+	} else {
+		maxScore = variants[variantName].MaxScore
+	}
+
+	if limit <= 0 {
+		return perfectGames, errors.New("the limit must be a positive number")
+	}
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT
+			games.id,
+			games.num_players,
+			STRING_AGG(users.username, ', ' ORDER BY game_participants.seat) AS player_names,
+			games.seed,
+			games.datetime_started,
+			games.datetime_finished
+		FROM games
+			JOIN game_participants ON games.id = game_participants.game_id
+			JOIN users ON game_participants.user_id = users.id
+		WHERE games.variant_id = $1
+			AND games.score = $2
+			AND games.datetime_deleted IS NULL
+		GROUP BY games.id
+		ORDER BY games.datetime_finished - games.datetime_started, games.id
+		LIMIT $3
+	`, variantID, maxScore, limit); err != nil {
+		return perfectGames, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var perfectGame PerfectGameRow
+		if err := rows.Scan(
+			&perfectGame.ID,
+			&perfectGame.NumPlayers,
+			&perfectGame.PlayerNames,
+			&perfectGame.Seed,
+			&perfectGame.DatetimeStarted,
+			&perfectGame.DatetimeFinished,
+		); err != nil {
+			return perfectGames, err
+		}
+		perfectGames = append(perfectGames, perfectGame)
+	}
+
+	if err := rows.Err(); err != nil {
+		return perfectGames, err
+	}
+	rows.Close()
+
+	return perfectGames, nil
+}
+
+// GetStrikeDistribution returns how many of a user's games ended with each number of strikes
+// (from 0 to the maximum number of strikes)
+// Games that were terminated (or resigned) are excluded, since the strikes are not meaningful
+// Games from before the number of strikes was recorded are also excluded
+func (*Games) GetStrikeDistribution(userID int) (map[int]int, error) {
+	strikeDistribution := make(map[int]int)
+	for i := 0; i <= MaxStrikeNum; i++ {
+		strikeDistribution[i] = 0
+	}
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT
+			games.num_strikes,
+			COUNT(games.id) AS num_games
+		FROM games
+			JOIN game_participants ON games.id = game_participants.game_id
+		WHERE game_participants.user_id = $1
+			AND games.num_strikes BETWEEN 0 AND $2
+			AND games.datetime_deleted IS NULL
+			AND games.end_condition NOT IN ($3, $4, $5, $6, $7)
+		GROUP BY games.num_strikes
+	`,
+		userID,
+		MaxStrikeNum,
+		EndConditionTerminatedByPlayer,
+		EndConditionIdleTimeout,
+		EndConditionTerminatedByVote,
+		EndConditionAbandoned,
+		EndConditionResigned,
+	); err != nil {
+		return strikeDistribution, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var numStrikes int
+		var numGames int
+		if err := rows.Scan(&numStrikes, &numGames); err != nil {
+			return strikeDistribution, err
+		}
+		strikeDistribution[numStrikes] = numGames
+	}
+
+	if err := rows.Err(); err != nil {
+		return strikeDistribution, err
+	}
+	rows.Close()
+
+	return strikeDistribution, nil
+}
+
+// GetWinStreak returns the current win streak and the best win streak of a user in a variant
+// A win is a game that reached the maximum score for the variant
+// Games that did not run to completion (e.g. terminated, abandoned, or resigned games) are ignored
+// entirely, so they neither continue nor break a streak
+func (*Games) GetWinStreak(userID int, variantID int) (int, int, error) {
+	var maxScore int
+	if variantName, ok := variantIDMap[variantID]; !ok {
+		return 0, 0, errors.New("there is no variant with an ID of " + strconv.Itoa(variantID))
+	} else {
+		maxScore = variants[variantName].MaxScore
+	}
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT games.score
+		FROM games
+			JOIN game_participants ON games.id = game_participants.game_id
+		WHERE game_participants.user_id = $1
+			AND games.variant_id = $2
+			AND games.datetime_deleted IS NULL
+			AND games.end_condition NOT IN ($3, $4, $5, $6, $7)
+		ORDER BY games.datetime_finished, games.id
+	`,
+		userID,
+		variantID,
+		EndConditionTerminatedByPlayer,
+		EndConditionIdleTimeout,
+		EndConditionTerminatedByVote,
+		EndConditionAbandoned,
+		EndConditionResigned,
+	); err != nil {
+		return 0, 0, err
+	} else {
+		rows = v
+	}
+
+	current := 0
+	best := 0
+	for rows.Next() {
+		var score int
+		if err := rows.Scan(&score); err != nil {
+			return 0, 0, err
+		}
+
+		if score == maxScore {
+			current++
+			if current > best {
+				best = current
+			}
+		} else {
+			current = 0
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+	rows.Close()
+
+	return current, best, nil
+}
+
 func (*Games) GetOptions(databaseID int) (*Options, error) {
 	var options Options
 	var variantID int
@@ -697,13 +1743,15 @@ func (*Games) GetOptions(databaseID int) (*Options, error) {
 			time_base,
 			time_per_turn,
 			speedrun,
+			turn_time_limit,
 			card_cycle,
 			deck_plays,
 			empty_clues,
 			one_extra_card,
 			one_less_card,
 			all_or_nothing,
-			detrimental_characters
+			detrimental_characters,
+			allowed_characters
 		FROM games
 		WHERE games.id = $1
 	`, databaseID).Scan(
@@ -714,6 +1762,7 @@ func (*Games) GetOptions(databaseID int) (*Options, error) {
 		&options.TimeBase,
 		&options.TimePerTurn,
 		&options.Speedrun,
+		&options.TurnTimeLimit,
 		&options.CardCycle,
 		&options.DeckPlays,
 		&options.EmptyClues,
@@ -721,6 +1770,7 @@ func (*Games) GetOptions(databaseID int) (*Options, error) {
 		&options.OneLessCard,
 		&options.AllOrNothing,
 		&options.DetrimentalCharacters,
+		&options.AllowedCharacters,
 	); err != nil {
 		return &options, err
 	}
@@ -736,6 +1786,33 @@ func (*Games) GetOptions(databaseID int) (*Options, error) {
 	return &options, nil
 }
 
+// GetStartOptions returns the options needed to create a new table with the same configuration
+// as a game in the database (e.g. for a rematch)
+// Unlike "GetOptions()", the table name and the maximum number of players are filled in and the
+// fields that are only determined when a game starts are cleared
+func (g *Games) GetStartOptions(databaseID int) (*Options, error) {
+	var options *Options
+	if v, err := g.GetOptions(databaseID); err != nil {
+		return v, err
+	} else {
+		options = v
+	}
+
+	if err := db.QueryRow(context.Background(), `
+		SELECT name
+		FROM games
+		WHERE games.id = $1
+	`, databaseID).Scan(&options.TableName); err != nil {
+		return options, err
+	}
+
+	options.MaxPlayers = options.NumPlayers
+	options.NumPlayers = 0
+	options.StartingPlayer = 0
+
+	return options, nil
+}
+
 func (*Games) GetNumTurns(databaseID int) (int, error) {
 	var numTurns int
 	err := db.QueryRow(context.Background(), `
@@ -746,6 +1823,115 @@ func (*Games) GetNumTurns(databaseID int) (int, error) {
 	return numTurns, err
 }
 
+// GetFirstGameDate returns the time that a user started their first game
+// It returns the zero value if they have not played any games
+func (*Games) GetFirstGameDate(userID int) (time.Time, error) {
+	var firstGameDate sql.NullTime
+	if err := db.QueryRow(context.Background(), `
+		SELECT MIN(games.datetime_started)
+		FROM game_participants
+			JOIN games ON game_participants.game_id = games.id
+		WHERE game_participants.user_id = $1
+			AND games.datetime_deleted IS NULL
+	`, userID).Scan(&firstGameDate); err != nil {
+		return time.Time{}, err
+	}
+
+	return firstGameDate.Time, nil
+}
+
+// GetConcurrentPeak returns the highest number of games that were being played at the same time
+// within the given range, along with the time that the peak was first reached
+// Instead of using a self-join (which is quadratic), we stream the start and end times of every
+// game that started before the end of the range and sweep over the endpoints
+// (the end times are not filtered in the query, since games that never properly ended do not
+// have a meaningful end time)
+func (*Games) GetConcurrentPeak(from time.Time, to time.Time) (int, time.Time, error) {
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT datetime_started, datetime_finished
+		FROM games
+		WHERE datetime_started < $2
+	`, from, to); err != nil {
+		return 0, time.Time{}, err
+	} else {
+		rows = v
+	}
+
+	type ConcurrencyEvent struct {
+		Datetime time.Time
+		Delta    int
+	}
+	events := make([]ConcurrencyEvent, 0)
+
+	for rows.Next() {
+		var datetimeStarted time.Time
+		var datetimeFinished time.Time
+		if err := rows.Scan(&datetimeStarted, &datetimeFinished); err != nil {
+			return 0, time.Time{}, err
+		}
+
+		// Games that never properly ended (e.g. if the server crashed) are treated as ending at
+		// the range boundary
+		neverEnded := !datetimeFinished.After(datetimeStarted)
+		if !neverEnded && !datetimeFinished.After(from) {
+			// This game ended before the range
+			continue
+		}
+
+		// Clamp the game to the range
+		if datetimeStarted.Before(from) {
+			datetimeStarted = from
+		}
+		if neverEnded || datetimeFinished.After(to) {
+			datetimeFinished = to
+		}
+
+		events = append(
+			events,
+			ConcurrencyEvent{Datetime: datetimeStarted, Delta: 1},
+			ConcurrencyEvent{Datetime: datetimeFinished, Delta: -1},
+		)
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, time.Time{}, err
+	}
+	rows.Close()
+
+	// When a game ends at the exact same time that another one starts, they do not overlap,
+	// so ends must come before starts
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Datetime.Equal(events[j].Datetime) {
+			return events[i].Delta < events[j].Delta
+		}
+		return events[i].Datetime.Before(events[j].Datetime)
+	})
+
+	peak := 0
+	var peakDatetime time.Time
+	current := 0
+	for _, event := range events {
+		current += event.Delta
+		if current > peak {
+			peak = current
+			peakDatetime = event.Datetime
+		}
+	}
+
+	return peak, peakDatetime, nil
+}
+
+func (*Games) GetScore(databaseID int) (int, error) {
+	var score int
+	err := db.QueryRow(context.Background(), `
+		SELECT score
+		FROM games
+		WHERE games.id = $1
+	`, databaseID).Scan(&score)
+	return score, err
+}
+
 func (*Games) GetSeed(databaseID int) (string, error) {
 	var seed string
 	err := db.QueryRow(context.Background(), `
@@ -756,6 +1942,45 @@ func (*Games) GetSeed(databaseID int) (string, error) {
 	return seed, err
 }
 
+// VerifySeedDeterminism deals the deck for a named seed (e.g. the suffix of a "!seed" table) and
+// returns a hash of the card order
+// The hashes can be stored and compared over time to detect changes to the shuffle algorithm
+// The hash is a SHA-256 of every card in the shuffled deck as "[suit index],[rank];"
+// (the format of the string must never change so that old hashes remain valid)
+func (*Games) VerifySeedDeterminism(seed string, variantID int, numPlayers int) (string, error) {
+	var variantName string
+	if v, ok := variantIDMap[variantID]; !ok {
+		return "", errors.New("failed to find a definition for variant " + strconv.Itoa(variantID))
+	} else {
+		variantName = v
+	}
+
+	if numPlayers < 2 || numPlayers > 6 {
+		return "", errors.New("the number of players must be between 2 and 6, not " +
+			strconv.Itoa(numPlayers))
+	}
+
+	// Make a deck and shuffle it in the same way as the "commandTableStart()" function
+	g := &Game{ // nolint: exhaustivestruct
+		Options: &Options{ // nolint: exhaustivestruct
+			NumPlayers:  numPlayers,
+			VariantName: variantName,
+		},
+		ExtraOptions: &ExtraOptions{}, // nolint: exhaustivestruct
+		Seed:         getSeedPrefix(numPlayers, variantID) + seed,
+	}
+	g.InitDeck()
+	g.ShuffleDeck()
+
+	var deckString strings.Builder
+	for _, c := range g.Deck {
+		deckString.WriteString(strconv.Itoa(c.SuitIndex) + "," + strconv.Itoa(c.Rank) + ";")
+	}
+	deckHash := sha256.Sum256([]byte(deckString.String()))
+
+	return fmt.Sprintf("%x", deckHash), nil
+}
+
 func (*Games) GetDatetimes(databaseID int) (time.Time, time.Time, error) {
 	// The following line triggers a false positive on "govet";
 	// https://github.com/golangci/govet/issues/2
@@ -774,7 +1999,7 @@ type DBPlayer struct {
 	ID                  int
 	Name                string
 	CharacterAssignment int
-	CharacterMetadata   int
+	CharacterMetadata   int // -1 if the character has null metadata
 }
 
 func (*Games) GetPlayers(databaseID int) ([]*DBPlayer, error) {
@@ -800,14 +2025,22 @@ func (*Games) GetPlayers(databaseID int) ([]*DBPlayer, error) {
 
 	for rows.Next() {
 		var dbPlayer DBPlayer
+		var characterMetadataJSON []byte
 		if err := rows.Scan(
 			&dbPlayer.ID,
 			&dbPlayer.Name,
 			&dbPlayer.CharacterAssignment,
-			&dbPlayer.CharacterMetadata,
+			&characterMetadataJSON,
 		); err != nil {
 			return dbPlayers, err
 		}
+
+		if v, err := parseDBCharacterMetadata(characterMetadataJSON); err != nil {
+			return dbPlayers, err
+		} else {
+			dbPlayer.CharacterMetadata = v.Value()
+		}
+
 		dbPlayers = append(dbPlayers, &dbPlayer)
 	}
 
@@ -854,6 +2087,28 @@ func (*Games) GetPlayerSeeds(userID int, variantID int) ([]string, error) {
 	return seeds, nil
 }
 
+// GetSeedUsage returns whether any of the users have played a game on the given seed
+// Only the players of a game are recorded in the "game_participants" table,
+// so spectating a game on the seed does not count
+// Deleted games are intentionally included, since the players have still seen the deck
+func (*Games) GetSeedUsage(seed string, userIDs []int) (bool, error) {
+	if len(userIDs) == 0 {
+		return false, nil
+	}
+
+	var used bool
+	err := db.QueryRow(context.Background(), `
+		SELECT EXISTS (
+			SELECT game_participants.game_id
+			FROM game_participants
+				JOIN games ON game_participants.game_id = games.id
+			WHERE games.seed = $1
+				AND game_participants.user_id = ANY ($2)
+		)
+	`, seed, userIDs).Scan(&used)
+	return used, err
+}
+
 func (*Games) GetNotes(databaseID int, numPlayers int, noteSize int) ([][]string, error) {
 	allPlayersNotes := make([][]string, numPlayers)
 	for i := 0; i < numPlayers; i++ {
@@ -933,6 +2188,7 @@ func (*Games) GetProfileStats(userID int) (Stats, error) {
 				FROM games
 					JOIN game_participants ON games.id = game_participants.game_id
 				WHERE game_participants.user_id = $1
+					AND games.datetime_deleted IS NULL
 					AND games.speedrun = FALSE
 					AND games.end_condition = 1
 			) AS num_games_normal,
@@ -941,6 +2197,7 @@ func (*Games) GetProfileStats(userID int) (Stats, error) {
 				FROM games
 					JOIN game_participants ON games.id = game_participants.game_id
 				WHERE game_participants.user_id = $1
+					AND games.datetime_deleted IS NULL
 					AND games.speedrun = FALSE
 					AND games.end_condition != 1
 			) AS num_games_other,
@@ -956,6 +2213,7 @@ func (*Games) GetProfileStats(userID int) (Stats, error) {
 				FROM games
 					JOIN game_participants ON games.id = game_participants.game_id
 				WHERE game_participants.user_id = $1
+					AND games.datetime_deleted IS NULL
 					AND games.speedrun = FALSE
 			) AS time_played,
 			(
@@ -963,6 +2221,7 @@ func (*Games) GetProfileStats(userID int) (Stats, error) {
 				FROM games
 					JOIN game_participants ON games.id = game_participants.game_id
 				WHERE game_participants.user_id = $1
+					AND games.datetime_deleted IS NULL
 					AND games.speedrun = TRUE
 			) AS num_games_speedrun,
 			(
@@ -977,6 +2236,7 @@ func (*Games) GetProfileStats(userID int) (Stats, error) {
 				FROM games
 					JOIN game_participants ON games.id = game_participants.game_id
 				WHERE game_participants.user_id = $1
+					AND games.datetime_deleted IS NULL
 					AND games.speedrun = TRUE
 			) AS time_played_speedrun
 	`, userID).Scan(
@@ -1002,12 +2262,14 @@ func (*Games) GetGlobalStats() (Stats, error) {
 				SELECT COUNT(id)
 				FROM games
 				WHERE games.speedrun = FALSE
+					AND games.datetime_deleted IS NULL
 					AND games.end_condition = 1
 			) AS num_games_normal,
 			(
 				SELECT COUNT(id)
 				FROM games
 				WHERE games.speedrun = FALSE
+				AND games.datetime_deleted IS NULL
 				AND games.end_condition != 1
 			) AS num_games_other,
 			(
@@ -1022,11 +2284,13 @@ func (*Games) GetGlobalStats() (Stats, error) {
 				FROM games
 					JOIN game_participants ON games.id = game_participants.game_id
 				WHERE games.speedrun = FALSE
+					AND games.datetime_deleted IS NULL
 			) AS time_played,
 			(
 				SELECT COUNT(id)
 				FROM games
 				WHERE games.speedrun = TRUE
+					AND games.datetime_deleted IS NULL
 			) AS num_games_speedrun,
 			(
 				/*
@@ -1040,6 +2304,7 @@ func (*Games) GetGlobalStats() (Stats, error) {
 				FROM games
 					JOIN game_participants ON games.id = game_participants.game_id
 				WHERE games.speedrun = TRUE
+					AND games.datetime_deleted IS NULL
 			) AS time_played_speedrun
 	`).Scan(
 		&stats.NumGamesNormal,
@@ -1063,6 +2328,7 @@ func (*Games) GetVariantStats(variantID int) (Stats, error) {
 				SELECT COUNT(id)
 				FROM games
 				WHERE variant_id = $1
+					AND games.datetime_deleted IS NULL
 					AND speedrun = FALSE
 					AND games.end_condition = 1
 			) AS num_games_normal,
@@ -1070,6 +2336,7 @@ func (*Games) GetVariantStats(variantID int) (Stats, error) {
 				SELECT COUNT(id)
 				FROM games
 				WHERE variant_id = $1
+					AND games.datetime_deleted IS NULL
 					AND speedrun = FALSE
 					AND games.end_condition != 1
 			) AS num_games_other,
@@ -1085,12 +2352,14 @@ func (*Games) GetVariantStats(variantID int) (Stats, error) {
 				FROM games
 					JOIN game_participants ON games.id = game_participants.game_id
 				WHERE games.variant_id = $1
+					AND games.datetime_deleted IS NULL
 					AND games.speedrun = FALSE
 			) AS time_played,
 			(
 				SELECT COUNT(id)
 				FROM games
 				WHERE games.variant_id = $1
+					AND games.datetime_deleted IS NULL
 					AND games.speedrun = TRUE
 			) AS num_games_speedrun,
 			(
@@ -1105,6 +2374,7 @@ func (*Games) GetVariantStats(variantID int) (Stats, error) {
 				FROM games
 					JOIN game_participants ON games.id = game_participants.game_id
 				WHERE games.variant_id = $1
+					AND games.datetime_deleted IS NULL
 					AND games.speedrun = TRUE
 			) AS time_played_speedrun
 	`, variantID).Scan(
@@ -1119,3 +2389,89 @@ func (*Games) GetVariantStats(variantID int) (Stats, error) {
 
 	return stats, nil
 }
+
+// SerializedState is a snapshot of an ongoing table that is used to restore it after a crash
+type SerializedState struct {
+	// The table in the same JSON format as the files written by the "serializeTables()" function
+	TableJSON []byte
+	// The time that the snapshot was taken, which is needed to restore the clock of the player
+	// who was in the middle of their turn
+	DatetimeSaved time.Time
+}
+
+// SaveCheckpoint saves (or replaces) the snapshot of an ongoing table
+// Ongoing games do not have a database ID yet, so checkpoints are keyed by the table ID
+func (*Games) SaveCheckpoint(tableID uint64, state SerializedState) error {
+	_, err := db.Exec(context.Background(), `
+		INSERT INTO game_checkpoints (table_id, state, datetime_saved)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (table_id) DO UPDATE
+		SET
+			state = EXCLUDED.state,
+			datetime_saved = EXCLUDED.datetime_saved
+	`, int64(tableID), state.TableJSON, state.DatetimeSaved)
+	return err
+}
+
+func (*Games) LoadCheckpoint(tableID uint64) (SerializedState, error) {
+	var state SerializedState
+	err := db.QueryRow(context.Background(), `
+		SELECT state, datetime_saved
+		FROM game_checkpoints
+		WHERE table_id = $1
+	`, int64(tableID)).Scan(&state.TableJSON, &state.DatetimeSaved)
+	return state, err
+}
+
+func (*Games) GetCheckpointTableIDs() ([]uint64, error) {
+	tableIDs := make([]uint64, 0)
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT table_id
+		FROM game_checkpoints
+		ORDER BY table_id
+	`); err != nil {
+		return tableIDs, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var tableID int64
+		if err := rows.Scan(&tableID); err != nil {
+			return tableIDs, err
+		}
+		tableIDs = append(tableIDs, uint64(tableID))
+	}
+
+	if err := rows.Err(); err != nil {
+		return tableIDs, err
+	}
+	rows.Close()
+
+	return tableIDs, nil
+}
+
+func (*Games) DeleteCheckpoint(tableID uint64) error {
+	_, err := db.Exec(context.Background(), `
+		DELETE FROM game_checkpoints
+		WHERE table_id = $1
+	`, int64(tableID))
+	return err
+}
+
+// DeleteCheckpointsExcept removes the checkpoints for all of the tables that are no longer ongoing
+// (e.g. if a game was terminated)
+func (*Games) DeleteCheckpointsExcept(tableIDs []uint64) error {
+	tableIDsInt64 := make([]int64, 0, len(tableIDs))
+	for _, tableID := range tableIDs {
+		tableIDsInt64 = append(tableIDsInt64, int64(tableID))
+	}
+
+	_, err := db.Exec(context.Background(), `
+		DELETE FROM game_checkpoints
+		WHERE NOT (table_id = ANY($1::BIGINT[]))
+	`, tableIDsInt64)
+	return err
+}
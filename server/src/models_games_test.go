@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+// TestVerifySeedDeterminism ensures that known seeds still deal the same decks
+// These hashes are the decks that the seeds dealt when the shuffle algorithm was pinned
+// (see the "ShuffleDeck()" function)
+// Never change these values; if one of them no longer matches, then the shuffle algorithm has
+// changed and every game in the database would be dealt differently from how it was played
+func TestVerifySeedDeterminism(t *testing.T) {
+	tests := []struct {
+		seed       string
+		variantID  int
+		numPlayers int
+		deckHash   string
+	}{
+		{
+			seed:       "1",
+			variantID:  0, // No Variant
+			numPlayers: 2,
+			deckHash:   "297e2dbf286899a87f5274419e412df7f9a8aa6d9eff3f665574b248ff1abe21",
+		},
+		{
+			seed:       "1",
+			variantID:  0, // No Variant
+			numPlayers: 3,
+			deckHash:   "ac79880d9372a324dd9dbd7e8a22a41f7ff5e10a4068eaa7f9a4228cacae5c94",
+		},
+		{
+			seed:       "5",
+			variantID:  0, // No Variant
+			numPlayers: 4,
+			deckHash:   "639fe599448bed08f1c633853ae29b5cc7936bfee5cb67cc94485af85dafcc25",
+		},
+		{
+			seed:       "tutorial-hard-3p",
+			variantID:  0, // No Variant
+			numPlayers: 3,
+			deckHash:   "e7c147b30a38bb2340970f90424e29702a643e3e2c81547effa4b69feb9b9f9c",
+		},
+		{
+			seed:       "showmatch-game-1",
+			variantID:  1, // 6 Suits
+			numPlayers: 5,
+			deckHash:   "733876d3ee75560ba2f269692360d76b12bfb5c441ba3f2f26169de6bccae4d4",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		name := test.seed + "/v" + strconv.Itoa(test.variantID) + "/p" + strconv.Itoa(test.numPlayers)
+		t.Run(name, func(t *testing.T) {
+			deckHash, err := (&Games{}).VerifySeedDeterminism(test.seed, test.variantID, test.numPlayers)
+			if err != nil {
+				t.Fatalf("failed to compute the deck hash: %v", err)
+			}
+			if deckHash != test.deckHash {
+				t.Errorf("the deck has changed from %q to %q; "+
+					"the shuffle algorithm must not be modified", test.deckHash, deckHash)
+			}
+		})
+	}
+}
+
+func TestVerifySeedDeterminismErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		variantID  int
+		numPlayers int
+	}{
+		{
+			name:       "invalid variant",
+			variantID:  -1,
+			numPlayers: 2,
+		},
+		{
+			name:       "too few players",
+			variantID:  0,
+			numPlayers: 1,
+		},
+		{
+			name:       "too many players",
+			variantID:  0,
+			numPlayers: 7,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := (&Games{}).VerifySeedDeterminism("1", test.variantID, test.numPlayers); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
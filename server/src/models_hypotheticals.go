@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type Hypotheticals struct{}
+
+// Hypothetical is a saved line of play from a shared replay
+// The actions are performed on top of the real game, starting from "StartTurn"
+type Hypothetical struct {
+	ID              int           `json:"id"`
+	GameID          int           `json:"gameID"`
+	Name            string        `json:"name"`
+	StartTurn       int           `json:"startTurn"`
+	Actions         []*GameAction `json:"actions"`
+	DatetimeCreated time.Time     `json:"datetimeCreated"`
+}
+
+// Insert saves a new hypothetical and returns its ID
+// There can be any number of hypotheticals for a single game (e.g. branching from different turns)
+func (*Hypotheticals) Insert(
+	gameID int,
+	startTurn int,
+	actions []GameAction,
+	name string,
+) (int, error) {
+	var tx pgx.Tx
+	if v, err := db.Begin(context.Background()); err != nil {
+		return -1, err
+	} else {
+		tx = v
+	}
+	// Rolling back a transaction that has already been committed is a no-op
+	defer tx.Rollback(context.Background()) // nolint: errcheck
+
+	var hypotheticalID int
+	if err := tx.QueryRow(context.Background(), `
+		INSERT INTO hypotheticals (game_id, name, start_turn)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, gameID, name, startTurn).Scan(&hypotheticalID); err != nil {
+		return -1, err
+	}
+
+	if len(actions) > 0 {
+		SQLString := `
+			INSERT INTO hypothetical_actions (
+				hypothetical_id,
+				action_index,
+				type,
+				target,
+				value
+			)
+			VALUES %s
+		`
+		numArgsPerRow := 5
+		valueArgs := make([]interface{}, 0, numArgsPerRow*len(actions))
+		for i, action := range actions {
+			valueArgs = append(
+				valueArgs,
+				hypotheticalID,
+				i,
+				action.Type,
+				action.Target,
+				action.Value,
+			)
+		}
+		SQLString = getBulkInsertSQLSimple(SQLString, numArgsPerRow, len(actions))
+
+		// This is in the same transaction as the hypothetical itself so that we never leave behind
+		// a hypothetical with a partial list of actions
+		if _, err := tx.Exec(context.Background(), SQLString, valueArgs...); err != nil {
+			return -1, err
+		}
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		return -1, err
+	}
+
+	return hypotheticalID, nil
+}
+
+// GetByGame returns all of the hypotheticals for a game, ordered by the turn that they start on
+func (*Hypotheticals) GetByGame(gameID int) ([]*Hypothetical, error) {
+	hypotheticals := make([]*Hypothetical, 0)
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT
+			id,
+			game_id,
+			name,
+			start_turn,
+			datetime_created
+		FROM hypotheticals
+		WHERE game_id = $1
+		ORDER BY start_turn, id
+	`, gameID); err != nil {
+		return hypotheticals, err
+	} else {
+		rows = v
+	}
+
+	hypotheticalMap := make(map[int]*Hypothetical)
+	for rows.Next() {
+		hypothetical := Hypothetical{ // nolint: exhaustivestruct
+			Actions: make([]*GameAction, 0),
+		}
+		if err := rows.Scan(
+			&hypothetical.ID,
+			&hypothetical.GameID,
+			&hypothetical.Name,
+			&hypothetical.StartTurn,
+			&hypothetical.DatetimeCreated,
+		); err != nil {
+			return hypotheticals, err
+		}
+
+		hypotheticals = append(hypotheticals, &hypothetical)
+		hypotheticalMap[hypothetical.ID] = &hypothetical
+	}
+
+	if err := rows.Err(); err != nil {
+		return hypotheticals, err
+	}
+	rows.Close()
+
+	if len(hypotheticals) == 0 {
+		return hypotheticals, nil
+	}
+
+	// Get the actions for every hypothetical at once
+	if v, err := db.Query(context.Background(), `
+		SELECT
+			hypothetical_actions.hypothetical_id,
+			hypothetical_actions.type,
+			hypothetical_actions.target,
+			hypothetical_actions.value
+		FROM hypothetical_actions
+			JOIN hypotheticals ON hypothetical_actions.hypothetical_id = hypotheticals.id
+		WHERE hypotheticals.game_id = $1
+		ORDER BY hypothetical_actions.hypothetical_id, hypothetical_actions.action_index
+	`, gameID); err != nil {
+		return hypotheticals, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var hypotheticalID int
+		var action GameAction
+		if err := rows.Scan(
+			&hypotheticalID,
+			&action.Type,
+			&action.Target,
+			&action.Value,
+		); err != nil {
+			return hypotheticals, err
+		}
+
+		// A hypothetical could have been inserted in between the two queries
+		if hypothetical, ok := hypotheticalMap[hypotheticalID]; ok {
+			hypothetical.Actions = append(hypothetical.Actions, &action)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return hypotheticals, err
+	}
+	rows.Close()
+
+	return hypotheticals, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// ModLog is an audit log of moderator actions
+// It is append-only, so there are intentionally no functions to update or delete entries
+// (the user IDs are only ever changed when a user is merged with "Users.Merge()")
+type ModLog struct{}
+
+// These are the values for the "action" column of the "mod_log" table
+const (
+	ModLogActionBan         = "ban"
+	ModLogActionMute        = "mute"
+	ModLogActionDeleteGame  = "deleteGame"
+	ModLogActionRestoreGame = "restoreGame"
+	ModLogActionResign      = "resign"
+)
+
+// ModLogEntry mirrors the "mod_log" table row
+type ModLogEntry struct {
+	ID              int       `json:"id"`
+	ModeratorID     int       `json:"moderatorID"` // 0 is the server
+	TargetUserID    int       `json:"targetUserID"`
+	Action          string    `json:"action"`
+	Reason          string    `json:"reason"`
+	DatetimeCreated time.Time `json:"datetimeCreated"`
+}
+
+func (*ModLog) Insert(moderatorID int, targetUserID int, action string, reason string) error {
+	_, err := db.Exec(context.Background(), `
+		INSERT INTO mod_log (moderator_id, target_user_id, action, reason)
+		VALUES ($1, $2, $3, $4)
+	`, moderatorID, targetUserID, action, reason)
+	return err
+}
+
+// GetByTarget returns every action that was taken against a user, with the newest first
+func (*ModLog) GetByTarget(targetUserID int) ([]ModLogEntry, error) {
+	return getModLogEntries(`
+		SELECT id, moderator_id, target_user_id, action, reason, datetime_created
+		FROM mod_log
+		WHERE target_user_id = $1
+		ORDER BY datetime_created DESC, id DESC
+	`, targetUserID)
+}
+
+// GetByModerator returns every action that a moderator has taken, with the newest first
+func (*ModLog) GetByModerator(moderatorID int) ([]ModLogEntry, error) {
+	return getModLogEntries(`
+		SELECT id, moderator_id, target_user_id, action, reason, datetime_created
+		FROM mod_log
+		WHERE moderator_id = $1
+		ORDER BY datetime_created DESC, id DESC
+	`, moderatorID)
+}
+
+func getModLogEntries(SQLString string, userID int) ([]ModLogEntry, error) {
+	entries := make([]ModLogEntry, 0)
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), SQLString, userID); err != nil {
+		return entries, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var entry ModLogEntry
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.ModeratorID,
+			&entry.TargetUserID,
+			&entry.Action,
+			&entry.Reason,
+			&entry.DatetimeCreated,
+		); err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return entries, err
+	}
+	rows.Close()
+
+	return entries, nil
+}
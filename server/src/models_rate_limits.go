@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimits tracks the recent actions of each user that are rate-limited
+type RateLimits struct{}
+
+// These are the values for the "action" column of the "rate_limits" table
+const (
+	RateLimitActionTableCreate = "tableCreate"
+)
+
+func (*RateLimits) Record(userID int, action string) error {
+	_, err := db.Exec(context.Background(), `
+		INSERT INTO rate_limits (user_id, action)
+		VALUES ($1, $2)
+	`, userID, action)
+	return err
+}
+
+// CountRecent returns the number of times that a user has performed an action within the window
+// The window is rolling (i.e. it ends right now), so that a user cannot reset their quota by
+// waiting for a particular time of day
+func (*RateLimits) CountRecent(userID int, action string, window time.Duration) (int, error) {
+	var count int
+	err := db.QueryRow(context.Background(), `
+		SELECT COUNT(id)
+		FROM rate_limits
+		WHERE user_id = $1
+			AND action = $2
+			AND datetime_created > $3
+	`, userID, action, time.Now().Add(-window)).Scan(&count)
+	return count, err
+}
+
+// PruneOlderThan deletes the entries that were recorded before the given time
+func (*RateLimits) PruneOlderThan(t time.Time) error {
+	_, err := db.Exec(context.Background(), `
+		DELETE FROM rate_limits
+		WHERE datetime_created < $1
+	`, t)
+	return err
+}
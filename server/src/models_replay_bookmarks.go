@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type ReplayBookmarks struct{}
+
+// ReplayBookmark is a comment on a specific turn of a replay
+// "Turn" is 0-indexed (i.e. it is the same as a shared replay segment)
+type ReplayBookmark struct {
+	ID              int       `json:"id"`
+	GameID          int       `json:"gameID"`
+	UserID          int       `json:"userID"`
+	Username        string    `json:"username"`
+	Turn            int       `json:"turn"`
+	Comment         string    `json:"comment"`
+	DatetimeCreated time.Time `json:"datetimeCreated"`
+}
+
+// Insert saves a new bookmark and returns its ID
+// It is assumed that the turn has already been validated with the "isReplayBookmarkTurnValid()"
+// function
+func (*ReplayBookmarks) Insert(gameID int, userID int, turn int, comment string) (int, error) {
+	var bookmarkID int
+	err := db.QueryRow(context.Background(), `
+		INSERT INTO replay_bookmarks (game_id, user_id, turn, comment)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, gameID, userID, turn, comment).Scan(&bookmarkID)
+	return bookmarkID, err
+}
+
+func (*ReplayBookmarks) Get(bookmarkID int) (bool, *ReplayBookmark, error) {
+	var bookmark ReplayBookmark
+	if err := db.QueryRow(context.Background(), `
+		SELECT
+			replay_bookmarks.id,
+			replay_bookmarks.game_id,
+			replay_bookmarks.user_id,
+			users.username,
+			replay_bookmarks.turn,
+			replay_bookmarks.comment,
+			replay_bookmarks.datetime_created
+		FROM replay_bookmarks
+			JOIN users ON replay_bookmarks.user_id = users.id
+		WHERE replay_bookmarks.id = $1
+	`, bookmarkID).Scan(
+		&bookmark.ID,
+		&bookmark.GameID,
+		&bookmark.UserID,
+		&bookmark.Username,
+		&bookmark.Turn,
+		&bookmark.Comment,
+		&bookmark.DatetimeCreated,
+	); errors.Is(err, pgx.ErrNoRows) {
+		return false, &bookmark, nil
+	} else if err != nil {
+		return false, &bookmark, err
+	}
+
+	return true, &bookmark, nil
+}
+
+// GetByGame returns all of the bookmarks for a game (from every user),
+// ordered by the turn that they are on
+func (*ReplayBookmarks) GetByGame(gameID int) ([]*ReplayBookmark, error) {
+	bookmarks := make([]*ReplayBookmark, 0)
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT
+			replay_bookmarks.id,
+			replay_bookmarks.game_id,
+			replay_bookmarks.user_id,
+			users.username,
+			replay_bookmarks.turn,
+			replay_bookmarks.comment,
+			replay_bookmarks.datetime_created
+		FROM replay_bookmarks
+			JOIN users ON replay_bookmarks.user_id = users.id
+		WHERE replay_bookmarks.game_id = $1
+		ORDER BY replay_bookmarks.turn, replay_bookmarks.id
+	`, gameID); err != nil {
+		return bookmarks, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var bookmark ReplayBookmark
+		if err := rows.Scan(
+			&bookmark.ID,
+			&bookmark.GameID,
+			&bookmark.UserID,
+			&bookmark.Username,
+			&bookmark.Turn,
+			&bookmark.Comment,
+			&bookmark.DatetimeCreated,
+		); err != nil {
+			return bookmarks, err
+		}
+		bookmarks = append(bookmarks, &bookmark)
+	}
+
+	if err := rows.Err(); err != nil {
+		return bookmarks, err
+	}
+	rows.Close()
+
+	return bookmarks, nil
+}
+
+// Update changes the comment of a bookmark
+// It returns false if the bookmark does not exist or if it belongs to a different user
+func (*ReplayBookmarks) Update(bookmarkID int, userID int, comment string) (bool, error) {
+	if tag, err := db.Exec(context.Background(), `
+		UPDATE replay_bookmarks
+		SET comment = $1
+		WHERE id = $2 AND user_id = $3
+	`, comment, bookmarkID, userID); err != nil {
+		return false, err
+	} else {
+		return tag.RowsAffected() > 0, nil
+	}
+}
+
+// Delete removes a bookmark
+// It returns false if the bookmark does not exist or if it belongs to a different user
+func (*ReplayBookmarks) Delete(bookmarkID int, userID int) (bool, error) {
+	if tag, err := db.Exec(context.Background(), `
+		DELETE FROM replay_bookmarks
+		WHERE id = $1 AND user_id = $2
+	`, bookmarkID, userID); err != nil {
+		return false, err
+	} else {
+		return tag.RowsAffected() > 0, nil
+	}
+}
@@ -17,6 +17,7 @@ func (*Seeds) UpdateNumGames(seed string) error {
 		SELECT COUNT(id)
 		FROM games
 		WHERE seed = $1
+			AND games.datetime_deleted IS NULL
 	`, seed).Scan(&numGames); err != nil {
 		return err
 	}
@@ -76,6 +77,7 @@ func (*Seeds) GetScoreFreqs(seed string) ([]*ScoreFreq, error) {
 		SELECT score, COUNT(*) as numtimes
 		FROM games
 		WHERE seed = $1
+			AND games.datetime_deleted IS NULL
 		GROUP BY score
 		ORDER BY score ASC
 	`, seed); errors.Is(err, pgx.ErrNoRows) {
@@ -105,6 +107,7 @@ func (s *Seeds) UpdateAll() error {
 	if v, err := db.Query(context.Background(), `
 		SELECT DISTINCT seed
 		FROM games
+		WHERE datetime_deleted IS NULL
 	`); err != nil {
 		return err
 	} else {
@@ -89,3 +89,15 @@ func (*UserFriends) GetMap(userID int) (map[int]struct{}, error) {
 
 	return friendMap, nil
 }
+
+// AreMutual returns whether or not both users have friended each other
+func (*UserFriends) AreMutual(userID1 int, userID2 int) (bool, error) {
+	var mutual bool
+	err := db.QueryRow(context.Background(), `
+		SELECT COUNT(*) = 2
+		FROM user_friends
+		WHERE (user_id = $1 AND friend_id = $2)
+			OR (user_id = $2 AND friend_id = $1)
+	`, userID1, userID2).Scan(&mutual)
+	return mutual, err
+}
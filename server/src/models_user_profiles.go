@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	MaxProfilePronounsLength = 30
+	MaxProfileBioLength      = 500
+)
+
+type UserProfiles struct{}
+
+// ProfileFields are the optional fields that a user can fill in on their profile
+// The zero value represents a user who has not filled in anything
+type ProfileFields struct {
+	Pronouns string `json:"pronouns"`
+	Bio      string `json:"bio"`
+	// Nil if the user has not picked a favorite variant
+	FavoriteVariantID *int `json:"favoriteVariantID"`
+}
+
+// Get returns the profile of a user
+// Users who have never edited their profile will not have a row,
+// in which case the zero value is returned
+func (*UserProfiles) Get(userID int) (ProfileFields, error) {
+	var fields ProfileFields
+	if err := db.QueryRow(context.Background(), `
+		SELECT
+			pronouns,
+			bio,
+			favorite_variant_id
+		FROM user_profiles
+		WHERE user_id = $1
+	`, userID).Scan(
+		&fields.Pronouns,
+		&fields.Bio,
+		&fields.FavoriteVariantID,
+	); errors.Is(err, pgx.ErrNoRows) {
+		return ProfileFields{}, nil
+	} else if err != nil {
+		return fields, err
+	}
+
+	return fields, nil
+}
+
+// Upsert replaces all of the fields of a user's profile
+// It returns an error if any of the fields are not valid
+func (*UserProfiles) Upsert(userID int, fields ProfileFields) error {
+	fields.Pronouns = strings.TrimSpace(fields.Pronouns)
+	fields.Bio = strings.TrimSpace(fields.Bio)
+
+	if utf8.RuneCountInString(fields.Pronouns) > MaxProfilePronounsLength {
+		return errors.New("pronouns cannot be longer than " +
+			strconv.Itoa(MaxProfilePronounsLength) + " characters")
+	}
+	if utf8.RuneCountInString(fields.Bio) > MaxProfileBioLength {
+		return errors.New("bios cannot be longer than " + strconv.Itoa(MaxProfileBioLength) +
+			" characters")
+	}
+	if fields.FavoriteVariantID != nil {
+		if _, ok := variantIDMap[*fields.FavoriteVariantID]; !ok {
+			return errors.New("variant " + strconv.Itoa(*fields.FavoriteVariantID) +
+				" does not exist")
+		}
+	}
+
+	// Each user has at most one row (since "user_id" is the primary key),
+	// so editing a profile is a single statement and setting the same fields twice is harmless
+	_, err := db.Exec(context.Background(), `
+		INSERT INTO user_profiles (user_id, pronouns, bio, favorite_variant_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET
+			pronouns = EXCLUDED.pronouns,
+			bio = EXCLUDED.bio,
+			favorite_variant_id = EXCLUDED.favorite_variant_id
+	`, userID, fields.Pronouns, fields.Bio, fields.FavoriteVariantID)
+	return err
+}
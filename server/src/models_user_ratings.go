@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type UserRatings struct{}
+
+// UserRatingsRow mirrors the "user_ratings" table row
+// Every combination of variant and number of players is rated separately
+type UserRatingsRow struct {
+	UserID     int
+	VariantID  int
+	NumPlayers int
+	Rating     float64
+	NumGames   int
+}
+
+// Get returns the rating for a user in a specific bucket
+// If the user has not played any rated games in the bucket yet,
+// then they start at the default rating
+func (*UserRatings) Get(userID int, variantID int, numPlayers int) (*UserRatingsRow, error) {
+	userRatingsRow := &UserRatingsRow{
+		UserID:     userID,
+		VariantID:  variantID,
+		NumPlayers: numPlayers,
+		Rating:     DefaultRating,
+		NumGames:   0,
+	}
+
+	if err := db.QueryRow(context.Background(), `
+		SELECT
+			rating,
+			num_games
+		FROM user_ratings
+		WHERE user_id = $1
+			AND variant_id = $2
+			AND num_players = $3
+	`, userID, variantID, numPlayers).Scan(
+		&userRatingsRow.Rating,
+		&userRatingsRow.NumGames,
+	); errors.Is(err, pgx.ErrNoRows) {
+		return userRatingsRow, nil
+	} else if err != nil {
+		return userRatingsRow, err
+	}
+
+	return userRatingsRow, nil
+}
+
+// Update sets the new rating for a user in a specific bucket after a rated game
+// (inserting a row for the bucket if it does not exist yet)
+func (*UserRatings) Update(userID int, variantID int, numPlayers int, rating float64) error {
+	_, err := db.Exec(context.Background(), `
+		INSERT INTO user_ratings (user_id, variant_id, num_players, rating, num_games)
+		VALUES ($1, $2, $3, $4, 1)
+		ON CONFLICT (user_id, variant_id, num_players) DO UPDATE
+		SET
+			rating = EXCLUDED.rating,
+			num_games = user_ratings.num_games + 1
+	`, userID, variantID, numPlayers, rating)
+	return err
+}
+
+// GetExpectedScore returns the average score of all of the other rated games in a bucket,
+// along with the amount of games that the average is based on
+func (*UserRatings) GetExpectedScore(
+	variantID int,
+	numPlayers int,
+	excludeGameID int,
+) (float64, int, error) {
+	var expectedScore float64
+	var numGames int
+	if err := db.QueryRow(context.Background(), `
+		SELECT
+			COALESCE(AVG(score), 0),
+			COUNT(id)
+		FROM games
+		WHERE variant_id = $1
+			AND num_players = $2
+			AND id != $3
+			AND datetime_deleted IS NULL
+			AND speedrun = FALSE
+			AND deck_plays = FALSE
+			AND empty_clues = FALSE
+			AND one_extra_card = FALSE
+			AND one_less_card = FALSE
+			AND all_or_nothing = FALSE
+			AND detrimental_characters = FALSE
+			AND end_condition NOT IN ($4, $5, $6, $7, $8)
+	`,
+		variantID,
+		numPlayers,
+		excludeGameID,
+		EndConditionTerminatedByPlayer,
+		EndConditionIdleTimeout,
+		EndConditionTerminatedByVote,
+		EndConditionAbandoned,
+		EndConditionResigned,
+	).Scan(&expectedScore, &numGames); err != nil {
+		return 0, 0, err
+	}
+
+	return expectedScore, numGames, nil
+}
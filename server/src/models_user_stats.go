@@ -188,6 +188,7 @@ func (*UserStats) Update(userID int, variantID int, stats *UserStatsRow) error {
 						JOIN game_participants
 							ON game_participants.game_id = games.id
 					WHERE game_participants.user_id = $1
+						AND games.datetime_deleted IS NULL
 						AND games.variant_id = $2
 						AND games.speedrun = FALSE
 				),
@@ -211,6 +212,7 @@ func (*UserStats) Update(userID int, variantID int, stats *UserStatsRow) error {
 						JOIN game_participants
 							ON game_participants.game_id = games.id
 					WHERE game_participants.user_id = $1
+						AND games.datetime_deleted IS NULL
 						AND games.score != 0
 						AND games.variant_id = $2
 						AND games.speedrun = FALSE
@@ -221,6 +223,7 @@ func (*UserStats) Update(userID int, variantID int, stats *UserStatsRow) error {
 						JOIN game_participants
 							ON game_participants.game_id = games.id
 					WHERE game_participants.user_id = $1
+						AND games.datetime_deleted IS NULL
 						AND games.score = 0
 						AND games.variant_id = $2
 						AND games.speedrun = FALSE
@@ -303,43 +306,9 @@ func (us *UserStats) UpdateAll(highestVariantID int) error {
 		// Calculate their best scores for every variant
 		statsMap := make(map[int]*UserStatsRow)
 		for variantID := 0; variantID <= highestVariantID; variantID++ {
-			// Go through the history, looking for games of this specific variant
-			stats := NewUserStatsRow()
-			totalScore := 0
-			for _, gameHistory := range gameHistoryList {
-				variant := variants[gameHistory.Options.VariantName]
-				if variant.ID != variantID {
-					continue
-				}
-
-				stats.NumGames++
-				totalScore += gameHistory.Score
-				if gameHistory.Score == 0 {
-					stats.NumStrikeouts++
-				}
-
-				bestScoresIndex := gameHistory.Options.NumPlayers - 2
-				bestScore := stats.BestScores[bestScoresIndex]
-				modifier := gameHistory.Options.GetModifier()
-				thisScore := &BestScore{ // nolint: exhaustivestruct
-					NumPlayers: gameHistory.Options.NumPlayers,
-					Score:      gameHistory.Score,
-					Modifier:   modifier,
-				}
-				if thisScore.IsBetterThan(bestScore) {
-					bestScore.Score = gameHistory.Score
-					bestScore.Modifier = modifier
-				}
+			if stats := getUserStatsFromHistory(gameHistoryList, variantID); stats != nil {
+				statsMap[variantID] = stats
 			}
-
-			if stats.NumGames == 0 {
-				// We do not need to insert a new row for this variant
-				continue
-			}
-
-			stats.AverageScore = float64(totalScore) / float64(stats.NumGames)
-
-			statsMap[variantID] = stats
 		}
 
 		// Bulk inserts rows for every variant that this user has played
@@ -353,6 +322,77 @@ func (us *UserStats) UpdateAll(highestVariantID int) error {
 	return nil
 }
 
+// Recalculate rebuilds the user's stats for a single variant from their games
+// (e.g. after one of their games was deleted by a moderator)
+func (us *UserStats) Recalculate(userID int, variantID int) error {
+	var gameIDs []int
+	if v, err := models.Games.GetGameIDsUser(userID, 0, 0); err != nil {
+		return err
+	} else {
+		gameIDs = v
+	}
+
+	var gameHistoryList []*GameHistory
+	if v, err := models.Games.GetHistory(gameIDs); err != nil {
+		return err
+	} else {
+		gameHistoryList = v
+	}
+
+	stats := getUserStatsFromHistory(gameHistoryList, variantID)
+	if stats == nil {
+		// They no longer have any games of this variant
+		_, err := db.Exec(context.Background(), `
+			DELETE FROM user_stats
+			WHERE user_id = $1
+				AND variant_id = $2
+		`, userID, variantID)
+		return err
+	}
+
+	return us.Update(userID, variantID, stats)
+}
+
+// getUserStatsFromHistory returns nil if there are no games of the given variant in the history
+func getUserStatsFromHistory(gameHistoryList []*GameHistory, variantID int) *UserStatsRow {
+	// Go through the history, looking for games of this specific variant
+	stats := NewUserStatsRow()
+	totalScore := 0
+	for _, gameHistory := range gameHistoryList {
+		variant := variants[gameHistory.Options.VariantName]
+		if variant.ID != variantID {
+			continue
+		}
+
+		stats.NumGames++
+		totalScore += gameHistory.Score
+		if gameHistory.Score == 0 {
+			stats.NumStrikeouts++
+		}
+
+		bestScoresIndex := gameHistory.Options.NumPlayers - 2
+		bestScore := stats.BestScores[bestScoresIndex]
+		modifier := gameHistory.Options.GetModifier()
+		thisScore := &BestScore{ // nolint: exhaustivestruct
+			NumPlayers: gameHistory.Options.NumPlayers,
+			Score:      gameHistory.Score,
+			Modifier:   modifier,
+		}
+		if thisScore.IsBetterThan(bestScore) {
+			bestScore.Score = gameHistory.Score
+			bestScore.Modifier = modifier
+		}
+	}
+
+	if stats.NumGames == 0 {
+		return nil
+	}
+
+	stats.AverageScore = float64(totalScore) / float64(stats.NumGames)
+
+	return stats
+}
+
 func (*UserStats) BulkInsert(userID int, statsMap map[int]*UserStatsRow) error {
 	SQLString := `
 		INSERT INTO user_stats (
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strconv"
 	"time"
 
 	"github.com/jackc/pgx/v4"
@@ -16,6 +17,8 @@ type User struct {
 	Username        string
 	PasswordHash    sql.NullString
 	OldPasswordHash sql.NullString
+	// Null for normal users; the ID of the kept user for users that were merged into another user
+	MergedInto sql.NullInt32
 }
 
 func (*Users) Insert(
@@ -42,6 +45,7 @@ func (*Users) Insert(
 		Username:        username,
 		PasswordHash:    sql.NullString{},
 		OldPasswordHash: sql.NullString{},
+		MergedInto:      sql.NullInt32{},
 	}, nil
 }
 
@@ -53,7 +57,8 @@ func (*Users) Get(username string) (bool, User, error) {
 			id,
 			username,
 			password_hash,
-			old_password_hash
+			old_password_hash,
+			merged_into
 		FROM users
 		WHERE username = $1
 	`, username).Scan(
@@ -61,6 +66,7 @@ func (*Users) Get(username string) (bool, User, error) {
 		&user.Username,
 		&user.PasswordHash,
 		&user.OldPasswordHash,
+		&user.MergedInto,
 	); errors.Is(err, pgx.ErrNoRows) {
 		return false, user, nil
 	} else if err != nil {
@@ -100,6 +106,17 @@ func (*Users) GetUsername(userID int) (string, error) {
 	return username, err
 }
 
+// IsMerged returns true if the user was merged into another user with the "Merge()" function
+func (*Users) IsMerged(userID int) (bool, error) {
+	var merged bool
+	err := db.QueryRow(context.Background(), `
+		SELECT merged_into IS NOT NULL
+		FROM users
+		WHERE id = $1
+	`, userID).Scan(&merged)
+	return merged, err
+}
+
 func (*Users) GetLastIP(username string) (string, error) {
 	var lastIP string
 	err := db.QueryRow(context.Background(), `
@@ -167,3 +184,425 @@ func (*Users) UpdatePassword(userID int, passwordHash string) error {
 	`, passwordHash, userID)
 	return err
 }
+
+// ErrUserNotFound is returned when a lookup by username does not match anyone
+var ErrUserNotFound = errors.New("user not found")
+
+// ProfileSummary is the compact summary that is shown for the "/playerinfo" command
+type ProfileSummary struct {
+	UserID   int
+	Username string
+	NumGames int
+	// The best score for a 3-player game on "No Variant"
+	BestScore3 int
+	// The "No Variant" rating for the number of players that they have played the most
+	Rating   float64
+	LastSeen time.Time
+}
+
+// GetProfileSummary gets all of the stats for the summary in a single query
+// The username is matched case-insensitively; ErrUserNotFound is returned if there is no match
+func (*Users) GetProfileSummary(username string) (*ProfileSummary, error) {
+	variantID := variants[DefaultVariantName].ID
+
+	var summary ProfileSummary
+	if err := db.QueryRow(context.Background(), `
+		SELECT
+			users.id,
+			users.username,
+			(
+				SELECT COUNT(game_participants.game_id)
+				FROM game_participants
+					JOIN games ON game_participants.game_id = games.id
+				WHERE game_participants.user_id = users.id
+					AND games.datetime_deleted IS NULL
+			) AS num_games,
+			COALESCE((
+				SELECT user_stats.best_score3
+				FROM user_stats
+				WHERE user_stats.user_id = users.id
+					AND user_stats.variant_id = $2
+			), 0) AS best_score3,
+			COALESCE((
+				SELECT user_ratings.rating
+				FROM user_ratings
+				WHERE user_ratings.user_id = users.id
+					AND user_ratings.variant_id = $2
+				ORDER BY user_ratings.num_games DESC, user_ratings.num_players
+				LIMIT 1
+			), $3) AS rating,
+			users.datetime_last_login
+		FROM users
+		WHERE users.normalized_username = $1
+	`, normalizeString(username), variantID, float64(DefaultRating)).Scan(
+		&summary.UserID,
+		&summary.Username,
+		&summary.NumGames,
+		&summary.BestScore3,
+		&summary.Rating,
+		&summary.LastSeen,
+	); errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUserNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &summary, nil
+}
+
+// TeammateInfo is a user that someone has played with, for the "recent teammates" list
+type TeammateInfo struct {
+	UserID   int       `json:"userID"`
+	Username string    `json:"username"`
+	NumGames int       `json:"numGames"`
+	LastGame time.Time `json:"lastGame"`
+}
+
+// GetRecentTeammates returns the users that someone has most recently played games with
+// (most recent first)
+// Each teammate only appears once, with the time of the latest game that they played together
+func (*Users) GetRecentTeammates(userID int, limit int) ([]TeammateInfo, error) {
+	teammates := make([]TeammateInfo, 0)
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT
+			users.id,
+			users.username,
+			COUNT(games.id) AS num_games,
+			MAX(games.datetime_finished) AS last_game
+		FROM game_participants AS self
+			JOIN games ON self.game_id = games.id
+			JOIN game_participants AS teammates ON games.id = teammates.game_id
+			JOIN users ON teammates.user_id = users.id
+		WHERE self.user_id = $1
+			AND teammates.user_id != $1
+			AND games.datetime_deleted IS NULL
+		GROUP BY users.id
+		ORDER BY last_game DESC, users.id
+		LIMIT $2
+	`, userID, limit); err != nil {
+		return teammates, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var teammate TeammateInfo
+		if err := rows.Scan(
+			&teammate.UserID,
+			&teammate.Username,
+			&teammate.NumGames,
+			&teammate.LastGame,
+		); err != nil {
+			return teammates, err
+		}
+		teammates = append(teammates, teammate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return teammates, err
+	}
+	rows.Close()
+
+	return teammates, nil
+}
+
+// GetActivityByMonth returns the number of games that a user played in each month,
+// keyed by "YYYY-MM" (in UTC)
+// Months without any games are not included
+func (*Users) GetActivityByMonth(userID int) (map[string]int, error) {
+	activity := make(map[string]int)
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT
+			TO_CHAR(games.datetime_started AT TIME ZONE 'UTC', 'YYYY-MM') AS month,
+			COUNT(games.id) AS num_games
+		FROM game_participants
+			JOIN games ON game_participants.game_id = games.id
+		WHERE game_participants.user_id = $1
+			AND games.datetime_deleted IS NULL
+		GROUP BY month
+	`, userID); err != nil {
+		return activity, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var month string
+		var numGames int
+		if err := rows.Scan(&month, &numGames); err != nil {
+			return activity, err
+		}
+		activity[month] = numGames
+	}
+
+	if err := rows.Err(); err != nil {
+		return activity, err
+	}
+	rows.Close()
+
+	return activity, nil
+}
+
+// GetPlayHeatmap returns the number of games that a user has started in each hour of each day of the
+// week, converted to the given IANA timezone (e.g. "America/New_York")
+// The matrix is always 7x24 and is indexed by weekday (with Sunday as 0) and then by hour
+// The conversion is done on each individual game (instead of on hourly buckets) so that daylight
+// saving time and timezones that are not offset by a whole number of hours are handled correctly
+func (*Users) GetPlayHeatmap(userID int, tz string) ([][]int, error) {
+	heatmap := make([][]int, 7)
+	for i := range heatmap {
+		heatmap[i] = make([]int, 24)
+	}
+
+	var location *time.Location
+	if v, err := time.LoadLocation(tz); err != nil {
+		return heatmap, errors.New("\"" + tz + "\" is not a valid timezone: " + err.Error())
+	} else {
+		location = v
+	}
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT games.datetime_started
+		FROM game_participants
+			JOIN games ON game_participants.game_id = games.id
+		WHERE game_participants.user_id = $1
+			AND games.datetime_deleted IS NULL
+	`, userID); err != nil {
+		return heatmap, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var datetimeStarted time.Time
+		if err := rows.Scan(&datetimeStarted); err != nil {
+			return heatmap, err
+		}
+		datetimeStarted = datetimeStarted.In(location)
+		heatmap[datetimeStarted.Weekday()][datetimeStarted.Hour()]++
+	}
+
+	if err := rows.Err(); err != nil {
+		return heatmap, err
+	}
+	rows.Close()
+
+	return heatmap, nil
+}
+
+// Merge consolidates a duplicate account into another account
+// All of the games, chat messages, tags, events, bookmarks, campaigns, friends, and moderator log
+// entries of the merged user are reassigned to the kept user and then the merged user is marked as
+// merged (the row is kept so that the username stays reserved and so that it cannot log in)
+// If both users played in the same game, the participant row with the earlier seat is kept
+// Everything happens in a single transaction so that a failure never leaves a half-merged user
+// Stats and ratings are not recalculated and the caller is responsible for logging out the users
+func (*Users) Merge(keepID int, mergeID int) error {
+	if keepID == mergeID {
+		return errors.New("cannot merge user " + strconv.Itoa(keepID) + " into itself")
+	}
+
+	var tx pgx.Tx
+	if v, err := db.Begin(context.Background()); err != nil {
+		return err
+	} else {
+		tx = v
+	}
+	// Rolling back a transaction that has already been committed is a no-op
+	defer tx.Rollback(context.Background()) // nolint: errcheck
+
+	// Lock both users so that they cannot be merged concurrently
+	var numUsers int
+	if err := tx.QueryRow(context.Background(), `
+		SELECT COUNT(*)
+		FROM (
+			SELECT id
+			FROM users
+			WHERE id IN ($1, $2)
+				AND merged_into IS NULL
+			FOR UPDATE
+		) AS unmerged_users
+	`, keepID, mergeID).Scan(&numUsers); err != nil {
+		return err
+	}
+	if numUsers != 2 {
+		return errors.New("users " + strconv.Itoa(keepID) + " and " + strconv.Itoa(mergeID) +
+			" must both exist and not already be merged")
+	}
+
+	// Normally, deleting a participant row deletes the entire game with the
+	// "delete_game_upon_participant_deletion" trigger
+	// This setting only lasts until the end of the transaction
+	if _, err := tx.Exec(
+		context.Background(),
+		"SELECT set_config('hanabi.merging_users', 'on', true)",
+	); err != nil {
+		return err
+	}
+
+	// Every query takes the kept user as "$1" and the merged user as "$2"
+	// Rows that would become duplicates of rows that the kept user already has are deleted first
+	SQLStrings := []string{
+		// If both accounts played in the same game, we keep the row with the earlier seat and drop
+		// the other, since a game cannot have the same user twice
+		`
+			DELETE FROM game_participants
+			USING game_participants AS earlier_participants
+			WHERE game_participants.user_id IN ($1, $2)
+				AND earlier_participants.user_id IN ($1, $2)
+				AND game_participants.user_id != earlier_participants.user_id
+				AND game_participants.game_id = earlier_participants.game_id
+				AND game_participants.seat > earlier_participants.seat
+		`,
+		`
+			UPDATE game_participants
+			SET user_id = $1
+			WHERE user_id = $2
+		`,
+		`
+			UPDATE chat_log
+			SET user_id = $1
+			WHERE user_id = $2
+		`,
+		`
+			UPDATE chat_log_pm
+			SET user_id = $1
+			WHERE user_id = $2
+		`,
+		`
+			UPDATE chat_log_pm
+			SET recipient_id = $1
+			WHERE recipient_id = $2
+		`,
+		`
+			DELETE FROM game_tags
+			USING game_tags AS kept_tags
+			WHERE game_tags.user_id = $2
+				AND kept_tags.user_id = $1
+				AND game_tags.game_id = kept_tags.game_id
+				AND game_tags.tag = kept_tags.tag
+		`,
+		`
+			UPDATE game_tags
+			SET user_id = $1
+			WHERE user_id = $2
+		`,
+		`
+			DELETE FROM game_events
+			USING game_events AS kept_events
+			WHERE game_events.user_id = $2
+				AND kept_events.user_id = $1
+				AND game_events.game_id = kept_events.game_id
+				AND game_events.event_type = kept_events.event_type
+		`,
+		`
+			UPDATE game_events
+			SET user_id = $1
+			WHERE user_id = $2
+		`,
+		`
+			UPDATE replay_bookmarks
+			SET user_id = $1
+			WHERE user_id = $2
+		`,
+		// The profile of the merged user is only kept if the kept user has never made one
+		`
+			DELETE FROM user_profiles
+			WHERE user_id = $2
+				AND EXISTS (
+					SELECT user_id
+					FROM user_profiles
+					WHERE user_id = $1
+				)
+		`,
+		`
+			UPDATE user_profiles
+			SET user_id = $1
+			WHERE user_id = $2
+		`,
+		`
+			DELETE FROM campaign_members
+			USING campaign_members AS kept_members
+			WHERE campaign_members.user_id = $2
+				AND kept_members.user_id = $1
+				AND campaign_members.campaign_id = kept_members.campaign_id
+		`,
+		`
+			UPDATE campaign_members
+			SET user_id = $1
+			WHERE user_id = $2
+		`,
+		`
+			UPDATE rate_limits
+			SET user_id = $1
+			WHERE user_id = $2
+		`,
+		`
+			UPDATE mod_log
+			SET target_user_id = $1
+			WHERE target_user_id = $2
+		`,
+		`
+			UPDATE mod_log
+			SET moderator_id = $1
+			WHERE moderator_id = $2
+		`,
+	}
+	for _, friendsTable := range []string{"user_friends", "user_reverse_friends"} {
+		SQLStrings = append(
+			SQLStrings,
+			`
+				DELETE FROM `+friendsTable+`
+				WHERE user_id = $2
+					AND (
+						friend_id = $1
+						OR friend_id IN (
+							SELECT friend_id
+							FROM `+friendsTable+`
+							WHERE user_id = $1
+						)
+					)
+			`,
+			`
+				UPDATE `+friendsTable+`
+				SET user_id = $1
+				WHERE user_id = $2
+			`,
+			`
+				DELETE FROM `+friendsTable+`
+				WHERE friend_id = $2
+					AND (
+						user_id = $1
+						OR user_id IN (
+							SELECT user_id
+							FROM `+friendsTable+`
+							WHERE friend_id = $1
+						)
+					)
+			`,
+			`
+				UPDATE `+friendsTable+`
+				SET friend_id = $1
+				WHERE friend_id = $2
+			`,
+		)
+	}
+	SQLStrings = append(SQLStrings, `
+		UPDATE users
+		SET merged_into = $1
+		WHERE id = $2
+	`)
+
+	for _, SQLString := range SQLStrings {
+		if _, err := tx.Exec(context.Background(), SQLString, keepID, mergeID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(context.Background())
+}
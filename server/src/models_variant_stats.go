@@ -157,6 +157,7 @@ func (*VariantStats) Update(variantID int, maxScore int, stats VariantStatsRow)
 					SELECT COUNT(id)
 					FROM games
 					WHERE variant_id = $1
+						AND games.datetime_deleted IS NULL
 						AND speedrun = FALSE
 				),
 				best_score2 = $2,
@@ -168,6 +169,7 @@ func (*VariantStats) Update(variantID int, maxScore int, stats VariantStatsRow)
 					SELECT COUNT(id)
 					FROM games
 					WHERE variant_id = $1
+						AND games.datetime_deleted IS NULL
 						AND score = $7
 						AND speedrun = FALSE
 				),
@@ -179,6 +181,7 @@ func (*VariantStats) Update(variantID int, maxScore int, stats VariantStatsRow)
 					 SELECT COALESCE(AVG(score), 0)
 					 FROM games
 					 WHERE variant_id = $1
+						AND games.datetime_deleted IS NULL
 						AND score != 0
 						AND speedrun = FALSE
 				),
@@ -186,6 +189,7 @@ func (*VariantStats) Update(variantID int, maxScore int, stats VariantStatsRow)
 					SELECT COUNT(id)
 					FROM games
 					WHERE variant_id = $1
+						AND games.datetime_deleted IS NULL
 						AND score = 0
 						AND speedrun = FALSE
 				)
@@ -209,58 +213,151 @@ func (vs *VariantStats) UpdateAll(highestVariantID int, maxScores []int) error {
 	}
 
 	for variantID := 0; variantID <= highestVariantID; variantID++ {
-		// Check to see if any users have played a game of this variant
-		var numGames int
+		if err := vs.Recalculate(variantID, maxScores[variantID]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Recalculate rebuilds the stats for a single variant from the games in the database
+// (e.g. after a game was deleted by a moderator)
+func (vs *VariantStats) Recalculate(variantID int, maxScore int) error {
+	// Check to see if any users have played a game of this variant
+	var numGames int
+	if err := db.QueryRow(context.Background(), `
+		SELECT COUNT(id)
+		FROM games
+		WHERE variant_id = $1
+			AND games.datetime_deleted IS NULL
+	`, variantID).Scan(&numGames); err != nil {
+		return err
+	}
+	if numGames == 0 {
+		// There should not be a row for this variant
+		_, err := db.Exec(context.Background(), `
+			DELETE FROM variant_stats
+			WHERE variant_id = $1
+		`, variantID)
+		return err
+	}
+
+	// Update scores for players 2 through 6
+	stats := NewVariantStatsRow()
+	for numPlayers := 2; numPlayers <= 6; numPlayers++ {
+		overallBestScore := 0
+
+		// Get the score for this player count (using a modifier of 0)
+		var bestScore int
 		if err := db.QueryRow(context.Background(), `
-			SELECT COUNT(id)
+			/*
+			 * We enclose this query in an "COALESCE" so that it defaults to 0
+			 * (instead of NULL) if there have been 0 games played on this variant
+			 */
+			SELECT COALESCE(MAX(games.score), 0)
 			FROM games
 			WHERE variant_id = $1
-		`, variantID).Scan(&numGames); err != nil {
+				AND games.datetime_deleted IS NULL
+				AND num_players = $2
+				AND games.deck_plays = FALSE
+				AND games.empty_clues = FALSE
+				AND games.one_extra_card = FALSE
+				AND games.one_less_card = FALSE
+				AND games.all_or_nothing = FALSE
+		`, variantID, numPlayers).Scan(&bestScore); err != nil {
 			return err
 		}
-		if numGames == 0 {
-			// We do not need to insert a new row for this variant
-			continue
+
+		if bestScore > overallBestScore {
+			overallBestScore = bestScore
 		}
 
-		// Update scores for players 2 through 6
-		stats := NewVariantStatsRow()
-		for numPlayers := 2; numPlayers <= 6; numPlayers++ {
-			overallBestScore := 0
-
-			// Get the score for this player count (using a modifier of 0)
-			var bestScore int
-			if err := db.QueryRow(context.Background(), `
-				/*
-				 * We enclose this query in an "COALESCE" so that it defaults to 0
-				 * (instead of NULL) if there have been 0 games played on this variant
-				 */
-				SELECT COALESCE(MAX(games.score), 0)
-				FROM games
-				WHERE variant_id = $1
-					AND num_players = $2
-					AND games.deck_plays = FALSE
-					AND games.empty_clues = FALSE
-					AND games.one_extra_card = FALSE
-					AND games.one_less_card = FALSE
-					AND games.all_or_nothing = FALSE
-			`, variantID, numPlayers).Scan(&bestScore); err != nil {
-				return err
-			}
-
-			if bestScore > overallBestScore {
-				overallBestScore = bestScore
-			}
-
-			i := numPlayers - 2
-			stats.BestScores[i].Score = overallBestScore
+		i := numPlayers - 2
+		stats.BestScores[i].Score = overallBestScore
+	}
+
+	// Insert or update the row for this variant
+	return vs.Update(variantID, maxScore, stats)
+}
+
+const (
+	// Variants with fewer games than this do not have a meaningful max score rate
+	VariantDifficultyMinGames = 20
+)
+
+type VariantDifficulty struct {
+	VariantID     int     `json:"variantID"`
+	Name          string  `json:"name"`
+	NumGames      int     `json:"numGames"`
+	NumMaxScores  int     `json:"numMaxScores"`
+	MaxScoreRate  float64 `json:"maxScoreRate"`
+	LowConfidence bool    `json:"lowConfidence"`
+}
+
+// GetDifficultyStats returns the fraction of games that reached the maximum score for each
+// variant, ordered by variant ID
+// This is computed directly from the "games" table (instead of the cached "variant_stats" table)
+// so that games that did not run to completion (e.g. terminated, abandoned, or resigned games) can
+// be excluded; they are not losses
+// Variants that have never been played are included with 0 games
+func (*VariantStats) GetDifficultyStats() ([]VariantDifficulty, error) {
+	difficulties := make([]VariantDifficulty, 0)
+
+	// The maximum score for each variant is not stored in the database,
+	// so we pass them to the query
+	variantIDs, maxScores := getVariantMaxScores()
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT
+			max_scores.variant_id,
+			COUNT(games.id) AS num_games,
+			COUNT(games.id) FILTER (WHERE games.score = max_scores.max_score) AS num_max_scores
+		FROM UNNEST($1::INTEGER[], $2::INTEGER[]) AS max_scores (variant_id, max_score)
+			LEFT JOIN games ON games.variant_id = max_scores.variant_id
+				AND games.datetime_deleted IS NULL
+				AND games.speedrun = FALSE
+				AND games.end_condition NOT IN ($3, $4, $5, $6, $7)
+		GROUP BY max_scores.variant_id
+		ORDER BY max_scores.variant_id
+	`,
+		variantIDs,
+		maxScores,
+		EndConditionTerminatedByPlayer,
+		EndConditionIdleTimeout,
+		EndConditionTerminatedByVote,
+		EndConditionAbandoned,
+		EndConditionResigned,
+	); err != nil {
+		return difficulties, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var difficulty VariantDifficulty
+		if err := rows.Scan(
+			&difficulty.VariantID,
+			&difficulty.NumGames,
+			&difficulty.NumMaxScores,
+		); err != nil {
+			return difficulties, err
 		}
 
-		// Insert a new row for this variant
-		if err := vs.Update(variantID, maxScores[variantID], stats); err != nil {
-			return err
+		difficulty.Name = variantIDMap[difficulty.VariantID]
+		if difficulty.NumGames > 0 {
+			difficulty.MaxScoreRate = float64(difficulty.NumMaxScores) / float64(difficulty.NumGames)
 		}
+		difficulty.LowConfidence = difficulty.NumGames < VariantDifficultyMinGames
+
+		difficulties = append(difficulties, difficulty)
 	}
 
-	return nil
+	if err := rows.Err(); err != nil {
+		return difficulties, err
+	}
+	rows.Close()
+
+	return difficulties, nil
 }
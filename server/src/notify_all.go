@@ -30,7 +30,9 @@ func notifyAllTable(t *Table) {
 
 	sessionList := sessions.GetList()
 	for _, s := range sessionList {
-		s.NotifyTable(t)
+		if t.IsVisibleTo(s) {
+			s.NotifyTable(t)
+		}
 	}
 }
 
@@ -45,6 +47,21 @@ func notifyAllTableGone(t *Table) {
 	}
 }
 
+// notifyAllTableGoneInvisible removes a table from the table list of everyone who is not allowed
+// to see it (e.g. after a table is changed to be friends-only)
+func notifyAllTableGoneInvisible(t *Table) {
+	if !t.Visible {
+		return
+	}
+
+	sessionList := sessions.GetList()
+	for _, s := range sessionList {
+		if !t.IsVisibleTo(s) {
+			s.NotifyTableGone(t)
+		}
+	}
+}
+
 func notifyAllShutdown() {
 	sessionList := sessions.GetList()
 	for _, s := range sessionList {
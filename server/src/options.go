@@ -13,6 +13,7 @@ type Options struct {
 	TimeBase              int    `json:"timeBase"`
 	TimePerTurn           int    `json:"timePerTurn"`
 	Speedrun              bool   `json:"speedrun"`
+	TurnTimeLimit         bool   `json:"turnTimeLimit"`
 	CardCycle             bool   `json:"cardCycle"`
 	DeckPlays             bool   `json:"deckPlays"`
 	EmptyClues            bool   `json:"emptyClues"`
@@ -20,8 +21,11 @@ type Options struct {
 	OneLessCard           bool   `json:"oneLessCard"`
 	AllOrNothing          bool   `json:"allOrNothing"`
 	DetrimentalCharacters bool   `json:"detrimentalCharacters"`
-	TableName             string `json:"tableName,omitempty"`
-	MaxPlayers            int    `json:"maxPlayers,omitempty"`
+	// AllowedCharacters is the pool of character IDs that can be dealt in a game with detrimental
+	// characters; an empty pool means that every character is allowed
+	AllowedCharacters []int  `json:"allowedCharacters,omitempty"`
+	TableName         string `json:"tableName,omitempty"`
+	MaxPlayers        int    `json:"maxPlayers,omitempty"`
 }
 
 // ExtraOptions are extra specifications for the game; they are not recorded in the database
@@ -58,6 +62,7 @@ type OptionsJSON struct {
 	TimeBase              *int    `json:"timeBase,omitempty"`
 	TimePerTurn           *int    `json:"timePerTurn,omitempty"`
 	Speedrun              *bool   `json:"speedrun,omitempty"`
+	TurnTimeLimit         *bool   `json:"turnTimeLimit,omitempty"`
 	CardCycle             *bool   `json:"cardCycle,omitempty"`
 	DeckPlays             *bool   `json:"deckPlays,omitempty"`
 	EmptyClues            *bool   `json:"emptyClues,omitempty"`
@@ -77,6 +82,7 @@ func NewOptions() *Options {
 		TimeBase:              0,
 		TimePerTurn:           0,
 		Speedrun:              false,
+		TurnTimeLimit:         false,
 		CardCycle:             false,
 		DeckPlays:             false,
 		EmptyClues:            false,
@@ -84,6 +90,7 @@ func NewOptions() *Options {
 		OneLessCard:           false,
 		AllOrNothing:          false,
 		DetrimentalCharacters: false,
+		AllowedCharacters:     nil,
 		TableName:             "",
 		MaxPlayers:            0,
 	}
@@ -19,6 +19,12 @@ type Player struct {
 	Typing     bool
 	LastTyped  time.Time
 	VoteToKill bool
+	// Resigning is voted on separately from terminating,
+	// since a resigned game is not counted as a loss in the stats
+	VoteToResign bool
+	// The time that the player left an ongoing game
+	// Equal to the zero value if they are currently connected
+	DatetimeDisconnected time.Time
 }
 type PregameStats struct {
 	NumGames int           `json:"numGames"`
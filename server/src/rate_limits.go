@@ -0,0 +1,20 @@
+package main
+
+import (
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+// rateLimitsPruneSweep is meant to be called in a new goroutine
+// It periodically deletes the entries in the "rate_limits" table that are too old to count towards
+// any rate limit
+func rateLimitsPruneSweep() {
+	for {
+		time.Sleep(RateLimitPruneInterval)
+
+		if err := models.RateLimits.PruneOlderThan(time.Now().Add(-RateLimitPruneAge)); err != nil {
+			logger.Error("Failed to prune the old rate limit entries: " + err.Error())
+		}
+	}
+}
@@ -27,6 +27,17 @@ func serializeTables() bool {
 		if t.Running && !t.Replay {
 			logger.Info("Serializing table: " + strconv.FormatUint(t.ID, 10))
 
+			// Spectators are not serialized, but we want them to be able to resume the same view
+			// after the restart, so we record the seat that each of them is shadowing
+			t.ShadowingSeats = tables.GetDisconSpectatingSeats(t.ID)
+			for _, sp := range t.ActiveSpectators() {
+				t.ShadowingSeats[sp.UserID] = sp.ShadowingPlayerIndex
+			}
+
+			// The time that the active player has spent on this turn so far must be saved along with
+			// the game, or else their clock would be reset to the start of the turn after the restart
+			t.Game.SaveTurnTime()
+
 			// Several fields on the Table object and the Game object are set with `json:"-"` to prevent
 			// the JSON encoder from serializing them
 			// Otherwise, we would have to explicitly unset some fields here to avoid circular
@@ -115,6 +126,20 @@ func restoreTable(ctx context.Context, f os.FileInfo) bool {
 		logger.Fatal("Failed to unmarshal \"" + tablePath + "\": " + err.Error())
 		return false
 	}
+
+	restoreTableState(ctx, t)
+
+	if err := os.Remove(tablePath); err != nil {
+		logger.Fatal("Failed to delete \"" + tablePath + "\": " + err.Error())
+	}
+
+	return true
+}
+
+// restoreTableState re-initializes a table that was unmarshalled from JSON and adds it to the
+// tables map
+// The tables lock must be acquired before calling this function
+func restoreTableState(ctx context.Context, t *Table) {
 	t.Spectators = make([]*Spectator, 0)
 	t.KickedPlayers = make(map[int]struct{})
 	if t.ChatRead == nil {
@@ -140,12 +165,43 @@ func restoreTable(ctx context.Context, f os.FileInfo) bool {
 		// Ensure that all of the players are not present
 		// (they were presumably present and connected when the table serialization happened)
 		p.Present = false
+		if p.DatetimeDisconnected.IsZero() {
+			p.DatetimeDisconnected = time.Now()
+		}
 
 		// Restore the player relationships
 		tables.AddPlaying(p.UserID, t.ID)
 	}
 
-	if g.Options.Timed {
+	// Restore the spectators as disconnected spectators
+	// (so that they will be put back into the game with the same view when they reconnect)
+	for userID, shadowingSeat := range t.ShadowingSeats {
+		if !isValidShadowingSeat(t, shadowingSeat) {
+			shadowingSeat = -1
+		}
+		tables.RestoreDisconSpectating(userID, t.ID, shadowingSeat)
+	}
+	t.ShadowingSeats = make(map[int]int)
+
+	// Tables are saved at arbitrary times, so the active player has usually already used some of
+	// their time on this turn
+	// We count the time prior to the save (so that their clock is not reset back to the start of
+	// the turn), but not the time that the server was offline
+	if !g.Paused {
+		if g.TurnTimeBeforeSave > 0 {
+			g.Players[g.ActivePlayerIndex].Time -= g.TurnTimeBeforeSave
+			g.TurnTimeBeforePause += g.TurnTimeBeforeSave
+		}
+		g.DatetimeTurnBegin = time.Now()
+	}
+	g.TurnTimeBeforeSave = 0
+
+	if g.Options.Timed && g.Paused {
+		// The clock of the active player was already frozen when the game was paused,
+		// so the game will stay paused with the same amount of time remaining
+		logger.Info(t.GetName() + "Restored table is paused (since " +
+			formatTimestampUnix(g.DatetimePaused) + ").")
+	} else if g.Options.Timed {
 		// Give the current player some additional seconds to make up for the fact that they are
 		// forced to refresh
 		g.Players[g.ActivePlayerIndex].Time += 20 * time.Second
@@ -154,20 +210,18 @@ func restoreTable(ctx context.Context, f os.FileInfo) bool {
 		// function was never initiated; manually do this
 		activePlayer := g.Players[g.ActivePlayerIndex]
 		go g.CheckTimer(ctx, activePlayer.Time, g.Turn, g.PauseCount, activePlayer)
+	} else if g.Options.TurnTimeLimit && g.StartedTimer {
+		// Similarly, the active player gets a fresh clock, which starts out paused until they
+		// reconnect (since nobody is present yet)
+		g.StartTurnTimeLimit(ctx, TurnTimeLimit)
 	}
 
 	tables.Set(t.ID, t)
 	logger.Info(t.GetName() + "Restored table.")
 
-	if err := os.Remove(tablePath); err != nil {
-		logger.Fatal("Failed to delete \"" + tablePath + "\": " + err.Error())
-	}
-
 	// Restored tables will never be automatically terminated due to idleness because the
 	// "CheckIdle()" function was never initiated; manually do this
 	go t.CheckIdle(ctx)
-
-	return true
 }
 
 func restoreTableAction(t *Table, i int, a interface{}) {
@@ -295,6 +295,17 @@ func (s *Session) NotifySpectators(t *Table) {
 	})
 }
 
+func (s *Session) NotifyReplayBookmarks(t *Table, bookmarks []*ReplayBookmark) {
+	type ReplayBookmarksMessage struct {
+		TableID   uint64            `json:"tableID"`
+		Bookmarks []*ReplayBookmark `json:"bookmarks"`
+	}
+	s.Emit("replayBookmarks", &ReplayBookmarksMessage{
+		TableID:   t.ID,
+		Bookmarks: bookmarks,
+	})
+}
+
 func (s *Session) NotifyBoot(t *Table) {
 	type BootMessage struct {
 		TableID uint64
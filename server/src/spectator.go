@@ -28,6 +28,22 @@ type Spectator struct {
 	notes []string `json:"-"`
 }
 
+// GetShadowedPlayer returns the player that the spectator is viewing the game through,
+// or nil if they should see every hand
+// If the shadowed seat no longer exists (e.g. the player left the table before the game started),
+// then the spectator falls back to the omniscient view
+func (sp *Spectator) GetShadowedPlayer(g *Game) *GamePlayer {
+	if g == nil || !isValidShadowingSeat(g.Table, sp.ShadowingPlayerIndex) {
+		return nil
+	}
+
+	return g.Players[sp.ShadowingPlayerIndex]
+}
+
+func isValidShadowingSeat(t *Table, seat int) bool {
+	return seat >= 0 && seat < len(t.Players)
+}
+
 // The default value is conceptually an empty string for each card in the deck,
 // but we do not know the size of the deck until the game starts,
 // so we provide this accessor method so that you can only access the notes after the game starts.
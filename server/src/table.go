@@ -24,11 +24,17 @@ type Table struct {
 	// We keep track of players who have been kicked from the game
 	// so that we can prevent them from rejoining
 	KickedPlayers map[int]struct{} `json:"-"`
+	// We keep track of the seat that each spectator is shadowing (or -1 for the omniscient view)
+	// when the table is serialized so that they can resume the same view after a server restart
+	// (this is only filled in right before serialization)
+	ShadowingSeats map[int]int // Indexed by user ID
 
 	// This is the user ID of the person who started the table
 	// or the current leader of the shared replay
 	OwnerID int
 	Visible bool // Whether or not this table is shown to other users
+	// Whether or not this table is only shown to the users that the owner has friended
+	FriendsOnly bool
 	// This is an Argon2id hash generated from the plain-text password
 	// that the table creator sends us
 	PasswordHash   string
@@ -85,8 +91,11 @@ func NewTable(name string, ownerID int) *Table {
 		Spectators:    make([]*Spectator, 0),
 		KickedPlayers: make(map[int]struct{}),
 
+		ShadowingSeats: make(map[int]int),
+
 		OwnerID:        ownerID,
 		Visible:        true, // Tables are visible by default
+		FriendsOnly:    false,
 		PasswordHash:   "",
 		Running:        false,
 		Replay:         false,
@@ -234,6 +243,74 @@ func (t *Table) EndIdle(ctx context.Context) {
 	}
 }
 
+// IsAbandoned returns true if the game is ongoing and every player has been disconnected for longer
+// than the threshold
+// Spectators do not count, since they cannot make any progress in the game
+// Players with a zero "DatetimeDisconnected" have not disconnected (e.g. they are still loading
+// the game after it started), so they count as connected
+// The table lock is assumed to be acquired in this function
+func (t *Table) IsAbandoned(threshold time.Duration) bool {
+	if !t.Running || t.Replay {
+		return false
+	}
+
+	for _, p := range t.Players {
+		if p.Present ||
+			p.DatetimeDisconnected.IsZero() ||
+			time.Since(p.DatetimeDisconnected) < threshold {
+
+			return false
+		}
+	}
+
+	return true
+}
+
+// EndAbandoned is called when the players of a table have all been gone for a while
+// The table lock is assumed to be acquired in this function
+func (t *Table) EndAbandoned(ctx context.Context) {
+	logger.Info(t.GetName() + " Every player has been disconnected for too long; ending the game.")
+
+	// Since this is a function that changes a user's relationship to tables,
+	// we must acquires the tables lock to prevent race conditions
+	tables.Lock(ctx)
+	defer tables.Unlock(ctx)
+
+	// Unlike an idle timeout, we do not boot the spectators;
+	// they will be put into the shared replay like normal
+	s := t.GetOwnerSession()
+	commandAction(ctx, s, &CommandData{ // nolint: exhaustivestruct
+		TableID:      t.ID,
+		Type:         ActionTypeEndGame,
+		Target:       -1,
+		Value:        EndConditionAbandoned,
+		NoTableLock:  true,
+		NoTablesLock: true,
+
+		EndedByServer: true,
+	})
+}
+
+// IsVisibleTo returns whether or not the table should be shown in the lobby for the given user
+// The table lock is assumed to be acquired in this function
+func (t *Table) IsVisibleTo(s *Session) bool {
+	return t.Visible && t.IsOpenTo(s)
+}
+
+// IsOpenTo returns whether or not the given user is allowed to join a "friends only" table
+// Unlike the "IsVisibleTo()" function, this does not depend on whether the table is currently
+// shown in the lobby (e.g. the pregame of a restarted game is hidden)
+// The table lock is assumed to be acquired in this function
+func (t *Table) IsOpenTo(s *Session) bool {
+	if !t.FriendsOnly || s.UserID == t.OwnerID {
+		return true
+	}
+
+	// The reverse friends of a user are the people who have friended them
+	_, ok := s.ReverseFriends()[t.OwnerID]
+	return ok
+}
+
 func (t *Table) GetName() string {
 	g := t.Game
 	name := "Table #" + strconv.FormatUint(t.ID, 10) + " (" + t.Name + ") - "
@@ -264,6 +341,18 @@ func (t *Table) IsPlayer(userID int) bool {
 	return t.GetPlayerIndexFromID(userID) != -1
 }
 
+// GetReplayPlayerIndexFromName returns the index of the player in a replay that corresponds to a
+// username, or -1 if they did not play in the game
+// (the players in a replay are fake players, so it is not possible to search by user ID)
+func (t *Table) GetReplayPlayerIndexFromName(username string) int {
+	for i, p := range t.Players {
+		if p.Name == username {
+			return i
+		}
+	}
+	return -1
+}
+
 func (t *Table) GetSpectatorIndexFromID(userID int) int {
 	for i, sp := range t.Spectators {
 		if sp.UserID == userID {
@@ -430,6 +519,40 @@ func (t *Table) GetVotes() []int {
 	return votes
 }
 
+func (t *Table) ChangeResignVote(playerIndex int) bool {
+	newVote := !t.Players[playerIndex].VoteToResign
+	t.Players[playerIndex].VoteToResign = newVote
+	return newVote
+}
+
+func (t *Table) ShouldResignByVotes() bool {
+	count := 0
+	for _, sp := range t.Players {
+		if sp.VoteToResign {
+			count++
+		}
+	}
+
+	// In 2 player, there is no-one else to outvote the player who wants to resign,
+	// so one vote is enough
+	if len(t.Players) == 2 {
+		return count >= 1
+	}
+
+	// Otherwise, every player has to agree
+	return count == len(t.Players)
+}
+
+func (t *Table) GetResignVotes() []int {
+	votes := make([]int, 0)
+	for i, sp := range t.Players {
+		if sp.VoteToResign {
+			votes = append(votes, i)
+		}
+	}
+	return votes
+}
+
 func (t *Table) ActiveSpectators() []*Spectator {
 	activeSpectators := make([]*Spectator, 0)
 	for _, sp := range t.Spectators {
@@ -1,6 +1,10 @@
 package main
 
-import "github.com/Hanabi-Live/hanabi-live/logger"
+import (
+	"strconv"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
 
 /*
 	Notifications for both before and during a game
@@ -356,6 +360,23 @@ func (t *Table) NotifyProgress() {
 	}
 }
 
+// NotifyReplayBookmarks sends the people in a shared replay the current list of bookmarks
+// (after someone has added, edited, or deleted one)
+func (t *Table) NotifyReplayBookmarks() {
+	var bookmarks []*ReplayBookmark
+	if v, err := models.ReplayBookmarks.GetByGame(t.ExtraOptions.DatabaseID); err != nil {
+		logger.Error("Failed to get the bookmarks for game " +
+			strconv.Itoa(t.ExtraOptions.DatabaseID) + ": " + err.Error())
+		return
+	} else {
+		bookmarks = v
+	}
+
+	for _, sp := range t.ActiveSpectators() {
+		sp.Session.NotifyReplayBookmarks(t, bookmarks)
+	}
+}
+
 // NotifyBoot boots the people in a game or shared replay back to the lobby screen
 func (t *Table) NotifyBoot() {
 	if !t.Replay {
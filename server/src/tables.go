@@ -4,6 +4,7 @@ import (
 	"context"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Hanabi-Live/hanabi-live/logger"
 	"github.com/sasha-s/go-deadlock"
@@ -254,6 +255,29 @@ func (ts *Tables) GetDisconShadowingSeat(userID int) (int, bool) {
 	return shadowingSeat, ok
 }
 
+// GetDisconSpectatingSeats returns the disconnected spectators for a table,
+// indexed by user ID, with the seat that they were shadowing as the value
+func (ts *Tables) GetDisconSpectatingSeats(tableID uint64) map[int]int {
+	ts.mutex.RLock()
+	defer ts.mutex.RUnlock()
+
+	shadowingSeats := make(map[int]int)
+	for userID, disconTableID := range ts.disconSpectating {
+		if disconTableID == tableID {
+			shadowingSeats[userID] = ts.disconShadowing[userID]
+		}
+	}
+	return shadowingSeats
+}
+
+// RestoreDisconSpectating is the same as the "SetDisconSpectating()" function,
+// but it is meant to be called when restoring tables
+func (ts *Tables) RestoreDisconSpectating(userID int, tableID uint64, shadowingSeat int) {
+	// It is assumed that the tables mutex is locked when calling this function
+	ts.disconSpectating[userID] = tableID
+	ts.disconShadowing[userID] = shadowingSeat
+}
+
 func (ts *Tables) PrintDisconSpectating() {
 	// It is assumed that the tables mutex is locked when calling this function
 	logger.Debug("DisconSpectating relationships:")
@@ -330,6 +354,22 @@ func getTableAndLock(
 	return t, true
 }
 
+// FindAbandoned returns the IDs of the ongoing games where every player has been disconnected for
+// longer than the threshold
+// (ongoing games are not written to the database until they end, so we check the tables in memory)
+func (ts *Tables) FindAbandoned(ctx context.Context, threshold time.Duration) []uint64 {
+	tableIDs := make([]uint64, 0)
+	for _, t := range ts.GetList(true) {
+		t.Lock(ctx)
+		if t.IsAbandoned(threshold) {
+			tableIDs = append(tableIDs, t.ID)
+		}
+		t.Unlock(ctx)
+	}
+
+	return tableIDs
+}
+
 func getTableIDFromName(ctx context.Context, tableName string) (uint64, bool) {
 	tableList := tables.GetList(false)
 	for _, t := range tableList {
@@ -321,3 +321,17 @@ func variantsIsValidID(id int) bool {
 
 	return true
 }
+
+// getVariantMaxScores returns the ID and the maximum score of every variant as two parallel slices
+// This is used to pass the maximum scores to database queries (with "UNNEST"),
+// since they are not stored in the database
+func getVariantMaxScores() ([]int, []int) {
+	variantIDs := make([]int, 0, len(variantIDMap))
+	maxScores := make([]int, 0, len(variantIDMap))
+	for variantID, variantName := range variantIDMap {
+		variantIDs = append(variantIDs, variantID)
+		maxScores = append(maxScores, variants[variantName].MaxScore)
+	}
+
+	return variantIDs, maxScores
+}
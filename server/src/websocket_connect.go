@@ -300,7 +300,7 @@ func websocketConnectTableList(ctx context.Context, s *Session) {
 	tableMessageList := make([]*TableMessage, 0)
 	for _, t := range tableList {
 		t.Lock(ctx)
-		if t.Visible {
+		if t.IsVisibleTo(s) {
 			tableMessageList = append(tableMessageList, makeTableMessage(s, t))
 		}
 		t.Unlock(ctx)
@@ -135,6 +135,10 @@ func ban(s *Session) {
 		return
 	}
 
+	if err := models.ModLog.Insert(0, s.UserID, ModLogActionBan, "Triggered rate-limiting"); err != nil {
+		logger.Error("Failed to insert the moderator log entry for the ban: " + err.Error())
+	}
+
 	logoutUser(s.UserID)
 	logger.Info("Successfully banned user \"" + s.Username + "\" from IP address \"" + ip + "\".")
 }